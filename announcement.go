@@ -16,6 +16,17 @@ type Announcement struct {
 	Title     string   `json:"title"`
 }
 
+type AnnouncementComment struct {
+	ID             int      `json:"id"`
+	Content        string   `json:"content"`
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt,omitempty"`
+	CreatedBy      string   `json:"createdBy"`
+	ChannelID      string   `json:"channelId"`
+	AnnouncementID string   `json:"announcementId"`
+	Mentions       Mentions `json:"mentions,omitempty"`
+}
+
 type GetAnnouncementParams struct {
 	Before string
 	Limit  int
@@ -0,0 +1,396 @@
+package guildedgo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Param is a typed argument of a command, see Command.Params.
+// Positional params take the positional arguments in order, e.g. Params member and duration
+// turn "!mute @user 10m" into a *ServerMember and a time.Duration.
+type Param struct {
+	Name string
+	Type ArgType
+
+	// Optional params may be left out, they must come after the required ones
+	Optional bool
+
+	// Rest takes all remaining positional arguments joined by spaces, e.g. for a reason. It must be the last param
+	Rest bool
+
+	// Flag reads the value from --name=value instead of the positional arguments
+	Flag bool
+
+	// Description is shown by the help command
+	Description string
+}
+
+// ArgType converts the text of an argument into a value, e.g. a mention into a *ServerMember
+type ArgType interface {
+	// Name describes the expected input to the user, e.g. "member"
+	Name() string
+
+	// Convert returns the value of arg. Errors meant for the user are wrapped in an ArgumentError
+	// by the router, wrap other errors, e.g. failed requests, in an InternalError to report them instead.
+	Convert(ctx *CommandContext, arg string) (any, error)
+}
+
+// ArgumentError is an argument that is missing or couldn't be converted.
+// Its message is meant for the user, the router replies with it.
+type ArgumentError struct {
+	Param Param
+
+	// The argument as it was typed, empty if it is missing
+	Value string
+
+	// Why the argument couldn't be converted, nil if it is missing
+	Err error
+}
+
+func (e *ArgumentError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("Missing %s (%s)", e.Param.Name, e.Param.Type.Name())
+	}
+
+	return fmt.Sprintf("Invalid %s: %s", e.Param.Name, e.Err)
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// InternalError is returned by an ArgType when conversion failed for reasons other than the input,
+// e.g. a failed request. It is reported to Config.OnHandlerError instead of being shown to the user
+type InternalError struct {
+	Err error
+}
+
+func (e *InternalError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InternalError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ArgString is the argument as it was typed
+	ArgString ArgType = stringArg{}
+
+	// ArgInt is a whole number, an int
+	ArgInt ArgType = intArg{}
+
+	// ArgDuration is a duration like 10m, 1h30m or 7d, a time.Duration
+	ArgDuration ArgType = durationArg{}
+
+	// ArgMember is a member mention or user ID, a *ServerMember fetched with MembersService.GetServerMember
+	ArgMember ArgType = memberArg{}
+
+	// ArgRole is a role mention or role ID, the role ID as an int
+	ArgRole ArgType = roleArg{}
+
+	// ArgChannel is a channel mention or channel ID, a *ServerChannel fetched with ChannelService.GetChannel
+	ArgChannel ArgType = channelArg{}
+
+	// ArgEmote is a custom emote like <:name:id> or an emote ID, an *Emote with the ID and, for custom emotes, the name
+	ArgEmote ArgType = emoteArg{}
+)
+
+// ArgEnum is one of values, matched case-insensitively. The value is the string as it is in values
+func ArgEnum(values ...string) ArgType {
+	return enumArg(values)
+}
+
+type stringArg struct{}
+
+func (stringArg) Name() string { return "text" }
+
+func (stringArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	return arg, nil
+}
+
+type intArg struct{}
+
+func (intArg) Name() string { return "number" }
+
+func (intArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a whole number", arg)
+	}
+
+	return n, nil
+}
+
+type durationArg struct{}
+
+func (durationArg) Name() string { return "duration" }
+
+func (durationArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	d, err := parseDuration(arg)
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf("%q is not a duration like 10m, 1h30m or 7d", arg)
+	}
+
+	return d, nil
+}
+
+// parseDuration extends time.ParseDuration with days and weeks, e.g. 1w2d12h
+func parseDuration(s string) (time.Duration, error) {
+	var total time.Duration
+
+	for _, unit := range []struct {
+		suffix string
+		d      time.Duration
+	}{{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}} {
+		before, after, found := strings.Cut(s, unit.suffix)
+		if !found {
+			continue
+		}
+
+		n, err := strconv.Atoi(before)
+		if err != nil {
+			return 0, err
+		}
+
+		total += time.Duration(n) * unit.d
+		s = after
+	}
+
+	if s == "" {
+		return total, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return total + d, nil
+}
+
+type memberArg struct{}
+
+func (memberArg) Name() string { return "member" }
+
+func (memberArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	id := mentionID(arg, "<@", ">")
+	if id == "" {
+		return nil, fmt.Errorf("%q is not a member, mention them or use their ID", arg)
+	}
+
+	// Mentions in the message only list users that exist
+	users := ctx.Event.Message.Mentions.Users
+	if len(users) > 0 && strings.HasPrefix(arg, "<") && !slices.ContainsFunc(users, func(u MentionsUser) bool {
+		return u.ID == id
+	}) {
+		return nil, fmt.Errorf("couldn't find the member %s", arg)
+	}
+
+	member, err := ctx.Client.Members.GetServerMember(ctx.Event.ServerID, id)
+	if IsNotFound(err) || IsBadRequest(err) {
+		return nil, fmt.Errorf("couldn't find the member %s", arg)
+	}
+	if err != nil {
+		return nil, &InternalError{Err: err}
+	}
+
+	return member, nil
+}
+
+type roleArg struct{}
+
+func (roleArg) Name() string { return "role" }
+
+func (roleArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	id := mentionID(arg, "<@&", ">")
+	if id == "" {
+		id = mentionID(arg, "<@", ">")
+	}
+
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a role, mention it or use its ID", arg)
+	}
+
+	// Mentions in the message only list roles that exist
+	roles := ctx.Event.Message.Mentions.Roles
+	if len(roles) > 0 && strings.HasPrefix(arg, "<") {
+		for _, role := range roles {
+			if role.ID == roleID {
+				return roleID, nil
+			}
+		}
+
+		return nil, fmt.Errorf("couldn't find the role %s", arg)
+	}
+
+	return roleID, nil
+}
+
+type channelArg struct{}
+
+func (channelArg) Name() string { return "channel" }
+
+func (channelArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	id := mentionID(arg, "<#", ">")
+	if id == "" {
+		return nil, fmt.Errorf("%q is not a channel, mention it or use its ID", arg)
+	}
+
+	channel, err := ctx.Client.Channel.GetChannel(id)
+	if IsNotFound(err) || IsBadRequest(err) || IsForbidden(err) {
+		return nil, fmt.Errorf("couldn't find the channel %s", arg)
+	}
+	if err != nil {
+		return nil, &InternalError{Err: err}
+	}
+
+	return channel, nil
+}
+
+type emoteArg struct{}
+
+func (emoteArg) Name() string { return "emote" }
+
+func (emoteArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	name := ""
+	id := arg
+
+	// Custom emotes are written as <:name:id> in the message content
+	if strings.HasPrefix(arg, "<:") && strings.HasSuffix(arg, ">") {
+		name, id, _ = strings.Cut(arg[len("<:"):len(arg)-len(">")], ":")
+	}
+
+	emoteID, err := strconv.Atoi(id)
+	if err != nil || emoteID <= 0 {
+		return nil, fmt.Errorf("%q is not an emote, use a custom emote or its ID", arg)
+	}
+
+	return &Emote{ID: emoteID, Name: name}, nil
+}
+
+type enumArg []string
+
+func (e enumArg) Name() string { return strings.Join(e, ", ") }
+
+func (e enumArg) Convert(ctx *CommandContext, arg string) (any, error) {
+	for _, v := range e {
+		if strings.EqualFold(v, arg) {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%q is not one of %s", arg, e.Name())
+}
+
+// mentionID returns the ID in a mention like <@id>, or arg itself if it is a plain ID.
+// It is empty if arg is a different kind of mention or contains spaces
+func mentionID(arg, open, close string) string {
+	if strings.HasPrefix(arg, open) && strings.HasSuffix(arg, close) {
+		arg = arg[len(open) : len(arg)-len(close)]
+	} else if strings.HasPrefix(arg, "<") {
+		return ""
+	}
+
+	if arg == "" || strings.ContainsAny(arg, " <>@#&") {
+		return ""
+	}
+
+	return arg
+}
+
+// convert converts the arguments of ctx according to params, the values are stored in ctx
+func convert(ctx *CommandContext, params []Param) error {
+	ctx.values = make(map[string]any, len(params))
+
+	positional := ctx.Args
+	for _, p := range params {
+		var arg string
+		var ok bool
+
+		switch {
+		case p.Flag:
+			arg, ok = ctx.Flags[p.Name]
+		case p.Rest:
+			arg, ok = strings.Join(positional, " "), len(positional) > 0
+			positional = nil
+		case len(positional) > 0:
+			arg, ok = positional[0], true
+			positional = positional[1:]
+		}
+
+		if !ok {
+			if p.Optional {
+				continue
+			}
+
+			return &ArgumentError{Param: p}
+		}
+
+		v, err := p.Type.Convert(ctx, arg)
+		if err != nil {
+			var internal *InternalError
+			if errors.As(err, &internal) {
+				return err
+			}
+
+			return &ArgumentError{Param: p, Value: arg, Err: err}
+		}
+
+		ctx.values[p.Name] = v
+	}
+
+	return nil
+}
+
+// Value returns the converted value of the param name, nil if it is optional and was left out
+func (ctx *CommandContext) Value(name string) any {
+	return ctx.values[name]
+}
+
+// Text returns the value of a param of type ArgString or ArgEnum
+func (ctx *CommandContext) Text(name string) string {
+	v, _ := ctx.values[name].(string)
+	return v
+}
+
+// Int returns the value of a param of type ArgInt
+func (ctx *CommandContext) Int(name string) int {
+	v, _ := ctx.values[name].(int)
+	return v
+}
+
+// Duration returns the value of a param of type ArgDuration
+func (ctx *CommandContext) Duration(name string) time.Duration {
+	v, _ := ctx.values[name].(time.Duration)
+	return v
+}
+
+// Member returns the value of a param of type ArgMember
+func (ctx *CommandContext) Member(name string) *ServerMember {
+	v, _ := ctx.values[name].(*ServerMember)
+	return v
+}
+
+// RoleID returns the value of a param of type ArgRole
+func (ctx *CommandContext) RoleID(name string) int {
+	v, _ := ctx.values[name].(int)
+	return v
+}
+
+// Emote returns the value of a param of type ArgEmote
+func (ctx *CommandContext) Emote(name string) *Emote {
+	v, _ := ctx.values[name].(*Emote)
+	return v
+}
+
+// Channel returns the value of a param of type ArgChannel
+func (ctx *CommandContext) Channel(name string) *ServerChannel {
+	v, _ := ctx.values[name].(*ServerChannel)
+	return v
+}
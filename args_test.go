@@ -0,0 +1,192 @@
+package guildedgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo"
+)
+
+// fakeAPI answers member and channel lookups and records the messages sent
+func fakeAPI(t *testing.T, sent chan<- guildedgo.MessageObject) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/servers/server/members/user":
+			w.Write([]byte(`{"member":{"user":{"id":"user","name":"User"},"roleIds":[1,2]}}`))
+		case "/servers/server/members/owner":
+			w.Write([]byte(`{"member":{"user":{"id":"owner","name":"Owner"},"roleIds":[1],"isOwner":true}}`))
+		case "/servers/server/roles/1":
+			w.Write([]byte(`{"role":{"id":1,"permissions":["CanReadChats"]}}`))
+		case "/servers/server/roles/2":
+			w.Write([]byte(`{"role":{"id":2,"permissions":["CanKickMembers"]}}`))
+		case "/channels/channel":
+			w.Write([]byte(`{"channel":{"id":"channel","name":"general"}}`))
+		case "/channels/channel/messages":
+			var msg guildedgo.MessageObject
+			json.NewDecoder(r.Body).Decode(&msg)
+			sent <- msg
+
+			w.Write([]byte(`{"message":{"id":"reply","channelId":"channel"}}`))
+		case "/servers/server/members/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"NotFound","message":"Not found"}`))
+		}
+	}))
+}
+
+func TestArgTypes(t *testing.T) {
+	api := fakeAPI(t, nil)
+	defer api.Close()
+
+	ctx := &guildedgo.CommandContext{
+		Client: guildedgo.NewClient(&guildedgo.Config{Token: "token", BaseURL: api.URL}),
+		Event:  &guildedgo.ChatMessageCreated{ServerID: "server"},
+	}
+	ctx.Event.Message.Mentions.Roles = []guildedgo.MentionsRole{{ID: 5}}
+	ctx.Event.Message.Mentions.Users = []guildedgo.MentionsUser{{ID: "user"}}
+
+	valid := []struct {
+		typ  guildedgo.ArgType
+		arg  string
+		want any
+	}{
+		{guildedgo.ArgInt, "-42", -42},
+		{guildedgo.ArgDuration, "1h30m", 90 * time.Minute},
+		{guildedgo.ArgDuration, "1w2d12h", 9*24*time.Hour + 12*time.Hour},
+		{guildedgo.ArgEnum("on", "off"), "OFF", "off"},
+		{guildedgo.ArgRole, "<@5>", 5},
+		{guildedgo.ArgRole, "7", 7},
+	}
+
+	for _, test := range valid {
+		got, err := test.typ.Convert(ctx, test.arg)
+		if err != nil || got != test.want {
+			t.Errorf("%s(%q) = %v, %v, want %v", test.typ.Name(), test.arg, got, err, test.want)
+		}
+	}
+
+	member, err := guildedgo.ArgMember.Convert(ctx, "<@user>")
+	if err != nil || member.(*guildedgo.ServerMember).User.Name != "User" {
+		t.Errorf("expected the member, got %v and %v", member, err)
+	}
+
+	emote, err := guildedgo.ArgEmote.Convert(ctx, "<:wave:90002>")
+	if err != nil || *emote.(*guildedgo.Emote) != (guildedgo.Emote{ID: 90002, Name: "wave"}) {
+		t.Errorf("expected the emote, got %v and %v", emote, err)
+	}
+
+	emote, err = guildedgo.ArgEmote.Convert(ctx, "90002")
+	if err != nil || emote.(*guildedgo.Emote).ID != 90002 {
+		t.Errorf("expected the emote ID, got %v and %v", emote, err)
+	}
+
+	channel, err := guildedgo.ArgChannel.Convert(ctx, "<#channel>")
+	if err != nil || channel.(*guildedgo.ServerChannel).Name != "general" {
+		t.Errorf("expected the channel, got %v and %v", channel, err)
+	}
+
+	invalid := []struct {
+		typ guildedgo.ArgType
+		arg string
+	}{
+		{guildedgo.ArgInt, "1.5"},
+		{guildedgo.ArgDuration, "soon"},
+		{guildedgo.ArgDuration, "-5m"},
+		{guildedgo.ArgEnum("on", "off"), "maybe"},
+		{guildedgo.ArgRole, "<@6>"},
+		{guildedgo.ArgRole, "<#channel>"},
+		{guildedgo.ArgMember, "<@missing>"},
+		{guildedgo.ArgMember, "missing"},
+		{guildedgo.ArgEmote, "wave"},
+		{guildedgo.ArgEmote, "<:wave:>"},
+		{guildedgo.ArgMember, "<#channel>"},
+		{guildedgo.ArgChannel, "<#missing>"},
+	}
+
+	for _, test := range invalid {
+		_, err := test.typ.Convert(ctx, test.arg)
+		var internal *guildedgo.InternalError
+		if err == nil || errors.As(err, &internal) {
+			t.Errorf("expected a user error for %s(%q), got %v", test.typ.Name(), test.arg, err)
+		}
+	}
+
+	_, err = guildedgo.ArgMember.Convert(ctx, "broken")
+	var internal *guildedgo.InternalError
+	if !errors.As(err, &internal) {
+		t.Errorf("expected an internal error for a failed request, got %v", err)
+	}
+}
+
+func TestCommandParams(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!mute <@user> soon"`),
+		chatMessageCreated("2", `,"content":"!mute"`),
+		chatMessageCreated("3", `,"content":"!mute <@user> 10m being loud --in=<#channel>"`),
+	)
+	defer srv.Close()
+
+	sent := make(chan guildedgo.MessageObject, 2)
+	api := fakeAPI(t, sent)
+	defer api.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	invocations := make(chan *guildedgo.CommandContext, 1)
+	c.CommandService.AddCommand(&guildedgo.Command{
+		CommandName: "mute",
+		Params: []guildedgo.Param{
+			{Name: "member", Type: guildedgo.ArgMember},
+			{Name: "duration", Type: guildedgo.ArgDuration},
+			{Name: "reason", Type: guildedgo.ArgString, Rest: true, Optional: true},
+			{Name: "in", Type: guildedgo.ArgChannel, Flag: true, Optional: true},
+		},
+		Handler: func(ctx *guildedgo.CommandContext) error {
+			invocations <- ctx
+			return nil
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	for _, want := range []string{
+		`Invalid duration: "soon" is not a duration like 10m, 1h30m or 7d`,
+		"Missing member (member)",
+	} {
+		select {
+		case msg := <-sent:
+			if msg.Content != want {
+				t.Errorf("expected the reply %q, got %q", want, msg.Content)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for the error reply")
+			t.FailNow()
+		}
+	}
+
+	select {
+	case ctx := <-invocations:
+		if ctx.Member("member").User.Id != "user" || ctx.Duration("duration") != 10*time.Minute ||
+			ctx.Text("reason") != "being loud" || ctx.Channel("in").Name != "general" {
+			t.Errorf("unexpected values %v, %v, %q and %v", ctx.Member("member"), ctx.Duration("duration"), ctx.Text("reason"), ctx.Channel("in"))
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the command")
+	}
+}
@@ -1,7 +1,7 @@
 package guildedgo
 
 import (
-	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 )
@@ -40,6 +40,23 @@ type CalendarEventRsvp struct {
 	UpdatedAt       string `json:"updatedAt"`
 }
 
+type CalendarEventComment struct {
+	ID              int      `json:"id"`
+	Content         string   `json:"content"`
+	CreatedAt       string   `json:"createdAt"`
+	UpdatedAt       string   `json:"updatedAt,omitempty"`
+	CalendarEventID int      `json:"calendarEventId"`
+	ChannelID       string   `json:"channelId"`
+	CreatedBy       string   `json:"createdBy"`
+	Mentions        Mentions `json:"mentions,omitempty"`
+}
+
+type CalendarEventSeries struct {
+	ID        string `json:"id"`
+	ServerID  string `json:"serverId"`
+	ChannelID string `json:"channelId"`
+}
+
 type CalenderEventObject struct {
 	Name        string `json:"name,omitempty"`
 	Description string `json:"description,omitempty"`
@@ -112,7 +129,7 @@ func (service *calendarService) CreateEvent(channelId string, event *CalenderEve
 
 	err := service.client.PostRequestV2(service.endpoints.Default(channelId), event, &calendarEvent)
 	if err != nil {
-		return nil, errors.New("Failed to create calendar event: " + err.Error())
+		return nil, fmt.Errorf("Failed to create calendar event: %w", err)
 	}
 
 	return &calendarEvent.Event, nil
@@ -147,7 +164,7 @@ func (service *calendarService) GetEvents(channelId string, options *GetEventsOp
 
 	err = service.client.GetRequestV2(url.String(), &calendarEvents)
 	if err != nil {
-		return nil, errors.New("Failed to get calendar events: " + err.Error())
+		return nil, fmt.Errorf("Failed to get calendar events: %w", err)
 	}
 
 	return calendarEvents.Events, nil
@@ -158,7 +175,7 @@ func (service *calendarService) GetEvent(channelId string, eventId int) (*Calend
 
 	err := service.client.GetRequestV2(service.endpoints.Get(channelId, eventId), &calendarEvent)
 	if err != nil {
-		return nil, errors.New("Failed to get calendar event: " + err.Error())
+		return nil, fmt.Errorf("Failed to get calendar event: %w", err)
 	}
 
 	return &calendarEvent.Event, nil
@@ -169,7 +186,7 @@ func (service *calendarService) UpdateEvent(channelId string, eventId int, event
 
 	err := service.client.PatchRequest(service.endpoints.Get(channelId, eventId), event, &calendarEvent)
 	if err != nil {
-		return nil, errors.New("Failed to update calendar event: " + err.Error())
+		return nil, fmt.Errorf("Failed to update calendar event: %w", err)
 	}
 
 	return &calendarEvent.Event, nil
@@ -178,7 +195,7 @@ func (service *calendarService) UpdateEvent(channelId string, eventId int, event
 func (service *calendarService) DeleteEvent(channelId string, eventId int) error {
 	_, err := service.client.DeleteRequest(service.endpoints.Get(channelId, eventId))
 	if err != nil {
-		return errors.New("Failed to delete calendar event: " + err.Error())
+		return fmt.Errorf("Failed to delete calendar event: %w", err)
 	}
 
 	return nil
@@ -189,7 +206,7 @@ func (service *calendarService) GetEventRSVP(channelId string, eventId int, user
 
 	err := service.client.GetRequestV2(service.endpoints.RsvpDefault(channelId, eventId, userId), &calendarEventRsvp)
 	if err != nil {
-		return nil, errors.New("Failed to get calendar event rsvp: " + err.Error())
+		return nil, fmt.Errorf("Failed to get calendar event rsvp: %w", err)
 	}
 
 	return &calendarEventRsvp.Rsvp, nil
@@ -200,7 +217,7 @@ func (service *calendarService) CreateOrUpdateEventRSVP(channelId string, eventI
 
 	err := service.client.GetRequestV2(service.endpoints.RsvpDefault(channelId, eventId, userId), &calendarEventRsvp)
 	if err != nil {
-		return nil, errors.New("Failed to create or update calendar event RSVP. Error: " + err.Error())
+		return nil, fmt.Errorf("Failed to create or update calendar event RSVP. Error: %w", err)
 	}
 
 	return &calendarEventRsvp.Rsvp, nil
@@ -209,7 +226,7 @@ func (service *calendarService) CreateOrUpdateEventRSVP(channelId string, eventI
 func (service *calendarService) DeleteEventRSVP(channelId string, eventId int, userId string) error {
 	_, err := service.client.DeleteRequest(service.endpoints.RsvpDefault(channelId, eventId, userId))
 	if err != nil {
-		return errors.New("Failed to delete calendar event RSVP. Error: " + err.Error())
+		return fmt.Errorf("Failed to delete calendar event RSVP. Error: %w", err)
 	}
 
 	return nil
@@ -220,7 +237,7 @@ func (service *calendarService) GetEventRSVPs(channelId string, eventId int) ([]
 
 	err := service.client.GetRequestV2(service.endpoints.Rsvps(channelId, eventId), &calendarEventRsvps)
 	if err != nil {
-		return nil, errors.New("Failed to get calendar event RSVPs. Error: " + err.Error())
+		return nil, fmt.Errorf("Failed to get calendar event RSVPs. Error: %w", err)
 	}
 
 	return calendarEventRsvps.Rsvps, nil
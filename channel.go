@@ -2,7 +2,6 @@ package guildedgo
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -162,13 +161,13 @@ func (service *channelService) CreateChannel(channelObject *NewChannelObject) (*
 
 	resp, err := service.client.PostRequest(endpoint, &channelObject)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to create new channel. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to create new channel. Error: \n%w", err)
 	}
 
 	var serverChannel ServerChannelCreated
 	err = json.Unmarshal(resp, &serverChannel)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to unmarshal ServerChannel response. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to unmarshal ServerChannel response. Error: \n%w", err)
 	}
 
 	return &serverChannel.Channel, nil
@@ -182,7 +181,7 @@ func (service *channelService) GetChannel(channelId string) (*ServerChannel, err
 	var serverChannel ServerChannelResponse
 	err := service.client.GetRequestV2(endpoint, &serverChannel)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to get channel. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to get channel. Error: \n%w", err)
 	}
 
 	return &serverChannel.Channel, nil
@@ -195,7 +194,7 @@ func (service *channelService) UpdateChannel(channelId string, channelObject *Up
 	var serverChannel ServerChannelResponse
 	err := service.client.PatchRequest(endpoint, &channelObject, &serverChannel)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to update  channel. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to update  channel. Error: \n%w", err)
 	}
 
 	return &serverChannel.Channel, nil
@@ -207,7 +206,7 @@ func (cs *channelService) DeleteChannel(channelId string) error {
 	endpoint := cs.endpoints.Get(channelId)
 	_, err := cs.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to delete channel. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to delete channel. Error: \n%w", err)
 	}
 
 	return nil
@@ -318,7 +317,7 @@ func (service *channelService) ArchiveChannel(channelId string) error {
 
 	err := service.client.PutRequestV2(endpoint, nil, nil)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to archive channel. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to archive channel. Error: \n%w", err)
 	}
 
 	return nil
@@ -329,7 +328,7 @@ func (service *channelService) RestoreChannel(channelId string) error {
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to restore channel. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to restore channel. Error: \n%w", err)
 	}
 
 	return nil
@@ -1,11 +1,16 @@
 package guildedgo
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/itschip/guildedgo/internal/gateway"
+	"github.com/itschip/guildedgo/internal/rest"
 )
 
 const (
@@ -14,13 +19,16 @@ const (
 
 type Client struct {
 	sync.RWMutex
-	wsMutex        sync.Mutex
 	Token          string
 	ServerID       string
-	client         *http.Client
-	conn           *websocket.Conn
+	rest           *rest.Client
+	ctx            context.Context
+	websocketURL   string
+	lastMessageID  string
+	dialer         *websocket.Dialer
+	userAgent      string
+	session        *gateway.Session
 	interrupt      chan os.Signal
-	listening      chan struct{}
 	Channel        ChannelService
 	Members        MembersService
 	Roles          RoleService
@@ -38,10 +46,22 @@ type Client struct {
 	Announcements  AnnouncementService
 	Category       CategoryService
 	Users          UserService
-	events         map[string][]Event
-	commands       map[string]Command
+	dispatcher     *gateway.Dispatcher
+	raw            *gateway.Dispatcher
+	pool           *gateway.Pool
+	router         *commandRouter
+
+	shutdownTimeout  time.Duration
+	missedHeartbeats int
+	logger           *slog.Logger
+
+	// parent is the client WithContext was called on, nil for clients made by NewClient
+	parent *Client
 }
 
+// Event is a registered callback.
+//
+// Deprecated: handlers are no longer stored as Event values, it is only kept for compatibility.
 type Event struct {
 	Callback func(*Client, any)
 	Type     *interface{}
@@ -50,15 +70,145 @@ type Event struct {
 type Config struct {
 	Token    string
 	ServerID string
+
+	// RetryPolicy controls how failed REST requests are retried. Nil disables retries,
+	// DefaultRetryPolicy returns a policy that retries idempotent requests on 5xx responses
+	RetryPolicy *RetryPolicy
+
+	// HTTPClient is used for all REST requests. Defaults to a client using Transport,
+	// or http.DefaultClient if Transport is nil too
+	HTTPClient *http.Client
+
+	// Transport is used for REST requests when HTTPClient is nil, e.g. to add a proxy
+	Transport http.RoundTripper
+
+	// BaseURL overrides the REST API URL, https://www.guilded.gg/api/v1 by default
+	BaseURL string
+
+	// WebsocketURL overrides the gateway URL, wss://www.guilded.gg/websocket/v1 by default
+	WebsocketURL string
+
+	// UserAgent is sent with every request and when connecting to the gateway
+	UserAgent string
+
+	// LastMessageID resumes a previous gateway session, e.g. from before a restart.
+	// Events Guilded sent since then are replayed, see Client.LastMessageID
+	LastMessageID string
+
+	// Workers is the number of goroutines calling event handlers, DefaultWorkers by default.
+	// Events of the same channel, or of the same server for events without a channel, are always handled in order
+	Workers int
+
+	// QueueSize is the number of events buffered per worker, DefaultQueueSize by default
+	QueueSize int
+
+	// QueuePolicy decides what happens to events when the queue of a worker is full, QueueBlock by default
+	QueuePolicy QueuePolicy
+
+	// OnHandlerError is called when an event or command handler panics or returns an error.
+	// The client keeps running either way. Errors are logged if it's nil
+	OnHandlerError func(err *HandlerError)
+
+	// ShutdownTimeout is how long Close and Run wait for Guilded to acknowledge the close frame
+	// and for event handlers to finish, DefaultShutdownTimeout by default
+	ShutdownTimeout time.Duration
+
+	// MissedHeartbeats is how many heartbeats Guilded may leave unanswered before the client reconnects,
+	// DefaultMissedHeartbeats by default
+	MissedHeartbeats int
+
+	// Logger receives the client's logs, slog.Default() if nil. Connection problems are logged at warn level,
+	// failed handlers and undecodable events at error level, requests and connection changes at debug level
+	Logger *slog.Logger
+}
+
+const (
+	DefaultWorkers   = gateway.DefaultWorkers
+	DefaultQueueSize = gateway.DefaultQueueSize
+
+	DefaultShutdownTimeout  = 10 * time.Second
+	DefaultMissedHeartbeats = gateway.DefaultMissedHeartbeats
+)
+
+// QueuePolicy decides what happens to events when event handlers can't keep up
+type QueuePolicy = gateway.Policy
+
+const (
+	// QueueBlock stops reading from the gateway until there is room in the queue.
+	// Handlers that are too slow for too long can make the connection time out and reconnect.
+	QueueBlock = gateway.Block
+
+	// QueueDrop discards events that don't fit in the queue
+	QueueDrop = gateway.Drop
+)
+
+// RetryPolicy decides if and when a failed REST request is sent again
+type RetryPolicy = rest.RetryPolicy
+
+// DefaultRetryPolicy retries idempotent requests up to 3 times on 5xx responses and transport errors,
+// with exponential backoff starting at 500ms
+func DefaultRetryPolicy() *RetryPolicy {
+	return rest.DefaultRetryPolicy()
 }
 
 func NewClient(config *Config) *Client {
 	c := &Client{
 		Token:    config.Token,
 		ServerID: config.ServerID,
-		client:   http.DefaultClient,
+		rest:     rest.New(),
+	}
+
+	c.rest.RetryPolicy = config.RetryPolicy
+	c.rest.HTTPClient = rest.HTTPClient(config.HTTPClient, config.Transport)
+	c.rest.BaseURL = config.BaseURL
+	c.rest.UserAgent = config.UserAgent
+
+	c.logger = config.Logger
+	if c.logger == nil {
+		c.logger = slog.Default()
+	}
+	c.rest.Logger = c.logger
+
+	c.lastMessageID = config.LastMessageID
+	c.websocketURL = config.WebsocketURL
+	if c.websocketURL == "" {
+		c.websocketURL = rest.DefaultWebsocketURL
 	}
 
+	c.dialer = rest.WebsocketDialer(c.rest.HTTPClient)
+
+	c.userAgent = config.UserAgent
+	if c.userAgent == "" {
+		c.userAgent = rest.DefaultUserAgent
+	}
+
+	c.initServices()
+
+	c.router = newCommandRouter()
+
+	c.dispatcher = gateway.NewDispatcher()
+	c.dispatcher.OnError = config.OnHandlerError
+	c.dispatcher.Logger = c.logger
+	c.raw = gateway.NewDispatcher()
+	c.raw.OnError = config.OnHandlerError
+	c.raw.Logger = c.logger
+	c.pool = gateway.NewPool(gateway.PoolConfig{
+		Workers:   config.Workers,
+		QueueSize: config.QueueSize,
+		Policy:    config.QueuePolicy,
+	})
+
+	c.shutdownTimeout = config.ShutdownTimeout
+	if c.shutdownTimeout <= 0 {
+		c.shutdownTimeout = DefaultShutdownTimeout
+	}
+
+	c.missedHeartbeats = config.MissedHeartbeats
+
+	return c
+}
+
+func (c *Client) initServices() {
 	c.Channel = &channelService{client: c}
 	c.Members = &membersService{client: c}
 	c.Roles = &roleService{client: c}
@@ -76,8 +226,52 @@ func NewClient(config *Config) *Client {
 	c.Announcements = &announcementService{client: c}
 	c.Users = &userService{client: c}
 	c.Category = &categoryService{client: c}
+}
+
+// WithContext returns a client whose service calls use ctx for cancellation and deadlines:
+//
+//	member, err := client.WithContext(ctx).Members.GetServerMember(serverID, userID)
+//
+// The returned client shares the REST transport, rate limits and event handlers with c.
+// It is meant for REST calls: handlers and commands registered through it receive c, so they don't
+// inherit ctx, and Open, Run, Close and Stats act on the connection of c.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("guildedgo: nil context")
+	}
+
+	c2 := &Client{
+		Token:      c.Token,
+		ServerID:   c.ServerID,
+		rest:       c.rest,
+		ctx:        ctx,
+		dispatcher: c.dispatcher,
+		raw:        c.raw,
+		pool:       c.pool,
+		router:     c.router,
+		logger:     c.logger,
+		parent:     c.base(),
+	}
+
+	c2.initServices()
 
-	c.events = make(map[string][]Event)
+	return c2
+}
+
+// base returns the client made by NewClient, which owns the connection and is passed to handlers
+func (c *Client) base() *Client {
+	if c.parent != nil {
+		return c.parent
+	}
 
 	return c
 }
+
+// Context returns the client's context, set by WithContext. It defaults to context.Background
+func (c *Client) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+
+	return context.Background()
+}
@@ -4,14 +4,78 @@ type CommandsBuilder struct {
 	Commands []Command
 }
 
+// Command is a chat command. Commands with a Handler or Subcommands are invoked with one of the command prefixes,
+// e.g. CommandName "ban" is invoked by "!ban user --reason=spam", and get the parsed arguments.
+// Their names and aliases are matched case-insensitively.
+// Other commands fire when the message content is exactly CommandName, prefix included.
 type Command struct {
 	CommandName string
 	Action      func(client *Client, v *ChatMessageCreated)
+
+	// Run is called instead of Action if it is set. An error it returns is passed to Config.OnHandlerError
+	Run func(client *Client, v *ChatMessageCreated) error
+
+	// Handler is called with the parsed invocation of the command. An error it returns is passed to Config.OnHandlerError
+	Handler func(ctx *CommandContext) error
+
+	// Params are converted before Handler is called, see CommandContext.Value.
+	// If an argument is missing or invalid, the router replies with an ArgumentError instead
+	Params []Param
+
+	// Aliases are other names the command is invoked with, e.g. "b" for "ban"
+	Aliases []string
+
+	// Subcommands are invoked by their name after the name of this command, e.g. "set" in "!config set prefix ?".
+	// A command without a Handler is only a group for its subcommands
+	Subcommands []Command
+
+	// Description says what the command does, it is shown by the help command
+	Description string
+
+	// Usage describes the arguments, e.g. "<member> [reason]". It is generated from Params if empty
+	Usage string
+
+	// Examples are invocations without the prefix, e.g. "ban @user spamming"
+	Examples []string
+
+	// Category groups commands in the help command. Subcommands are in the category of their parent if it's empty
+	Category string
+
+	// Hidden commands are left out of the help command
+	Hidden bool
+
+	// RequiredRoles lets only members with at least one of these roles use the command
+	RequiredRoles []int
+
+	// RequiredPermissions lets only members whose roles grant all of these permissions use the command, e.g. CanKickMembers
+	RequiredPermissions []string
+
+	// OwnerOnly lets only the owner of the server use the command.
+	// The owner passes RequiredRoles and RequiredPermissions of every command
+	OwnerOnly bool
+
+	// AllowedChannels limits the command to these channel IDs, it may be used in every channel if it's empty
+	AllowedChannels []string
+
+	// DeniedChannels are channel IDs the command can't be used in
+	DeniedChannels []string
 }
 
 type CommandService interface {
 	AddCommand(command *Command)
 	AddCommands(commands *CommandsBuilder)
+
+	// SetPrefixes replaces the prefixes commands with a Handler are invoked with, DefaultCommandPrefix by default
+	SetPrefixes(prefixes ...string)
+	Prefixes() []string
+
+	// SetBotID lets commands with a Handler also be invoked by mentioning the bot, e.g. "@Bot ban user".
+	// Messages of the bot itself are ignored
+	SetBotID(botID string)
+
+	// SetDeniedHandler replaces how members are told that they can't use a command, see DeniedHandler.
+	// By default the bot replies with the PermissionError
+	SetDeniedHandler(handler DeniedHandler)
 }
 
 type commandService struct {
@@ -21,12 +85,28 @@ type commandService struct {
 var _ CommandService = &commandService{}
 
 func (service *commandService) AddCommand(command *Command) {
-	service.client.Command(command.CommandName, command.Action)
+	service.client.router.add(service.client.base(), command)
 }
 
 func (service *commandService) AddCommands(builder *CommandsBuilder) {
 	// Is this the best way to do this? I'm not sure. - Thanks, Copilot
 	for _, command := range builder.Commands {
-		service.client.Command(command.CommandName, command.Action)
+		service.AddCommand(&command)
 	}
 }
+
+func (service *commandService) SetPrefixes(prefixes ...string) {
+	service.client.router.setPrefixes(prefixes)
+}
+
+func (service *commandService) Prefixes() []string {
+	return service.client.router.getPrefixes()
+}
+
+func (service *commandService) SetBotID(botID string) {
+	service.client.router.setBotID(botID)
+}
+
+func (service *commandService) SetDeniedHandler(handler DeniedHandler) {
+	service.client.router.setDeniedHandler(handler)
+}
@@ -1,7 +1,7 @@
 package guildedgo
 
 import (
-	"errors"
+	"fmt"
 	"strconv"
 )
 
@@ -45,7 +45,7 @@ func (s *docCommentService) Create(channelID string, docID int, content string)
 	var comment DocComment
 	err := s.client.PostRequestV2(s.endpoints.Default(channelID, docID), content, &comment)
 	if err != nil {
-		return nil, errors.New("error creating doc comment: " + err.Error())
+		return nil, fmt.Errorf("error creating doc comment: %w", err)
 	}
 
 	return &comment, nil
@@ -55,7 +55,7 @@ func (s *docCommentService) GetComments(channelID string, docID int) ([]DocComme
 	var comments []DocComment
 	err := s.client.GetRequestV2(s.endpoints.Default(channelID, docID), &comments)
 	if err != nil {
-		return nil, errors.New("error getting doc comments: " + err.Error())
+		return nil, fmt.Errorf("error getting doc comments: %w", err)
 	}
 
 	return comments, nil
@@ -65,7 +65,7 @@ func (s *docCommentService) GetDocComment(channelID string, docID int, commentID
 	var comment DocComment
 	err := s.client.GetRequestV2(s.endpoints.Get(channelID, docID, commentID), &comment)
 	if err != nil {
-		return nil, errors.New("error getting doc comment: " + err.Error())
+		return nil, fmt.Errorf("error getting doc comment: %w", err)
 	}
 
 	return &comment, nil
@@ -75,7 +75,7 @@ func (s *docCommentService) UpdateDocComment(channelID string, docID int, commen
 	var comment DocComment
 	err := s.client.PatchRequest(s.endpoints.Get(channelID, docID, commentID), content, &comment)
 	if err != nil {
-		return nil, errors.New("error updating doc comment: " + err.Error())
+		return nil, fmt.Errorf("error updating doc comment: %w", err)
 	}
 
 	return &comment, nil
@@ -84,7 +84,7 @@ func (s *docCommentService) UpdateDocComment(channelID string, docID int, commen
 func (s *docCommentService) DeleteDocComment(channelID string, docID int, commentID int) error {
 	_, err := s.client.DeleteRequest(s.endpoints.Get(channelID, docID, commentID))
 	if err != nil {
-		return errors.New("error deleting doc comment: " + err.Error())
+		return fmt.Errorf("error deleting doc comment: %w", err)
 	}
 
 	return nil
@@ -1,7 +1,7 @@
 package guildedgo
 
 import (
-	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 )
@@ -59,7 +59,7 @@ func (service *docsService) Create(channelId string) (*Doc, error) {
 
 	err := service.client.PostRequestV2("POST", endpoint, nil)
 	if err != nil {
-		return nil, errors.New("Error creating doc. Error: " + err.Error())
+		return nil, fmt.Errorf("Error creating doc. Error: %w", err)
 	}
 
 	return &docResponse.Doc, nil
@@ -70,7 +70,7 @@ func (service *docsService) GetDocs(channelId string, doc *DocObject) ([]Doc, er
 
 	url, err := url.Parse(service.endpoints.Default(channelId))
 	if err != nil {
-		return nil, errors.New("Error parsing URL. Error: " + err.Error())
+		return nil, fmt.Errorf("Error parsing URL. Error: %w", err)
 	}
 
 	query := url.Query()
@@ -85,7 +85,7 @@ func (service *docsService) GetDocs(channelId string, doc *DocObject) ([]Doc, er
 
 	err = service.client.GetRequestV2(url.String(), &docsResponse)
 	if err != nil {
-		return nil, errors.New("Error getting docs. Error: " + err.Error())
+		return nil, fmt.Errorf("Error getting docs. Error: %w", err)
 	}
 
 	return docsResponse.Docs, nil
@@ -98,7 +98,7 @@ func (service *docsService) GetDoc(channelId string, docId int) (*Doc, error) {
 
 	err := service.client.GetRequestV2(endpoint, &docResponse)
 	if err != nil {
-		return nil, errors.New("Error getting doc. Error: " + err.Error())
+		return nil, fmt.Errorf("Error getting doc. Error: %w", err)
 	}
 
 	return &docResponse.Doc, nil
@@ -111,7 +111,7 @@ func (service *docsService) UpdateDoc(channelId string, docId int, doc *DocObjec
 
 	err := service.client.PutRequestV2(endpoint, &doc, &docResponse)
 	if err != nil {
-		return nil, errors.New("Error updating doc. Error: " + err.Error())
+		return nil, fmt.Errorf("Error updating doc. Error: %w", err)
 	}
 
 	return &docResponse.Doc, nil
@@ -122,7 +122,7 @@ func (service *docsService) DeleteDoc(channelId string, docId int) error {
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New("Error deleting doc. Error: " + err.Error())
+		return fmt.Errorf("Error deleting doc. Error: %w", err)
 	}
 
 	return nil
@@ -1,20 +1,232 @@
 package guildedgo
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/itschip/guildedgo/internal/gateway"
+)
+
+// Subscription is a registered event handler, call Remove to unregister it
+type Subscription = gateway.Subscription
+
+// HandlerError is a panic in an event handler, or an error it returned. See Config.OnHandlerError
+type HandlerError = gateway.HandlerError
+
 // On listens to any event
-func (c *Client) On(event string, callback func(client *Client, v any)) {
-	c.events[event] = append(c.events[event], Event{
-		Callback: callback,
+func (c *Client) On(event string, callback func(client *Client, v any)) *Subscription {
+	return c.Handle(event, func(client *Client, v any) error {
+		callback(client, v)
+		return nil
+	})
+}
+
+// Handle listens to any event like On, an error returned by handler is passed to Config.OnHandlerError
+func (c *Client) Handle(event string, handler func(client *Client, v any) error) *Subscription {
+	base := c.base()
+	return c.dispatcher.Add(event, func(name string, v any) error {
+		return handler(base, v)
+	})
+}
+
+// Once listens to the next event named event only
+func (c *Client) Once(event string, callback func(client *Client, v any)) *Subscription {
+	base := c.base()
+	return c.dispatcher.Once(event, func(name string, v any) error {
+		callback(base, v)
+		return nil
+	})
+}
+
+// OnAny listens to every event, callback receives the event name along with the decoded value
+func (c *Client) OnAny(callback func(client *Client, event string, v any)) *Subscription {
+	base := c.base()
+	return c.dispatcher.Add(gateway.Any, func(name string, v any) error {
+		callback(base, name, v)
+		return nil
+	})
+}
+
+// OnEvent listens to the event T is decoded from, e.g.
+//
+//	guildedgo.OnEvent(c, func(c *guildedgo.Client, e *guildedgo.ChatMessageCreated) {})
+//
+// It panics if T is not an event type.
+func OnEvent[T any](c *Client, callback func(client *Client, e *T)) *Subscription {
+	return HandleEvent(c, func(client *Client, e *T) error {
+		callback(client, e)
+		return nil
+	})
+}
+
+// HandleEvent listens to the event T is decoded from like OnEvent,
+// an error returned by handler is passed to Config.OnHandlerError
+func HandleEvent[T any](c *Client, handler func(client *Client, e *T) error) *Subscription {
+	return c.Handle(EventName[T](), func(client *Client, v any) error {
+		e, ok := v.(*T)
+		if !ok {
+			return nil
+		}
+
+		return handler(client, e)
 	})
 }
 
+// EventName returns the name of the event T is decoded from. It panics if T is not an event type
+func EventName[T any]() string {
+	name, ok := gateway.NameOf[T](eventRegistry)
+	if !ok {
+		panic(fmt.Sprintf("guildedgo: %T is not an event", *new(T)))
+	}
+
+	return name
+}
+
+// WaitFor blocks until an event named event arrives that predicate accepts, and returns it.
+// predicate may be nil to accept the next event. It returns ctx's error if ctx is done first, e.g. to wait for a reply:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+//	defer cancel()
+//
+//	v, err := client.WaitFor(ctx, "ChatMessageCreated", func(v any) bool {
+//		e := v.(*guildedgo.ChatMessageCreated)
+//		return e.Message.CreatedBy == userID && e.Message.ChannelID == channelID
+//	})
+//
+// Waiters get events as soon as they are received, before the event middleware and the handlers,
+// so a handler can wait for the next event of its own channel.
+func (c *Client) WaitFor(ctx context.Context, event string, predicate func(v any) bool) (any, error) {
+	return c.dispatcher.Wait(ctx, event, predicate)
+}
+
+// WaitForEvent waits for the event T is decoded from like WaitFor. It panics if T is not an event type
+func WaitForEvent[T any](ctx context.Context, c *Client, predicate func(e *T) bool) (*T, error) {
+	v, err := c.WaitFor(ctx, EventName[T](), func(v any) bool {
+		e, ok := v.(*T)
+		return ok && (predicate == nil || predicate(e))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*T), nil
+}
+
+// Collector gathers events until it reaches a limit, times out or a stop condition is met, see Client.Collect
+type Collector = gateway.Collector
+
+// CollectOptions decide which events a Collector gathers and when it stops
+type CollectOptions = gateway.CollectorConfig
+
+// CollectorStopReason is why a Collector stopped, see Collector.Reason
+type CollectorStopReason = gateway.StopReason
+
+const (
+	CollectorLimit     = gateway.StopLimit
+	CollectorTimeout   = gateway.StopTimeout
+	CollectorCondition = gateway.StopCondition
+	CollectorCancelled = gateway.StopCancelled
+)
+
+// Collect starts gathering the events named event that pass opts.Filter, e.g. the reactions to a message for a minute:
+//
+//	collector := client.Collect("ChannelMessageReactionCreated", guildedgo.CollectOptions{
+//		Filter: func(v any) bool {
+//			return v.(*guildedgo.ChannelMessageReactionCreated).Reaction.MessageID == messageID
+//		},
+//		Timeout: time.Minute,
+//	})
+//
+//	reactions, err := collector.Wait(ctx)
+//
+// The collector runs until it is stopped, so set at least one of Max, Timeout and Until, or call Stop.
+// Like WaitFor, it gets events as soon as they are received, so it can be started from a handler.
+func (c *Client) Collect(event string, opts CollectOptions) *Collector {
+	return c.dispatcher.Collect(event, opts)
+}
+
 // Command listens to ChatMessageCreated and fires a func when the message content matches the command
-func (c *Client) Command(cmd string, callback func(client *Client, v *ChatMessageCreated)) {
-	c.On("ChatMessageCreated", func(client *Client, v any) {
-		data, ok := v.(*ChatMessageCreated)
-		if ok {
-			if data.Message.Content == cmd {
-				callback(client, data)
-			}
+func (c *Client) Command(cmd string, callback func(client *Client, v *ChatMessageCreated)) *Subscription {
+	return c.handleCommand(cmd, func(client *Client, v *ChatMessageCreated) error {
+		callback(client, v)
+		return nil
+	})
+}
+
+func (c *Client) handleCommand(cmd string, handler func(client *Client, v *ChatMessageCreated) error) *Subscription {
+	return HandleEvent(c, func(client *Client, data *ChatMessageCreated) error {
+		if data.Message.Content != cmd {
+			return nil
 		}
+
+		return handler(client, data)
+	})
+}
+
+// OnRaw listens to every message received from the gateway, before it is decoded and passed
+// through the event middleware. Events of types the library doesn't know yet are also passed
+// to the regular handlers as *RawEvent, e.g. c.On("SomeNewEvent", ...).
+func (c *Client) OnRaw(callback func(client *Client, e *RawEvent)) *Subscription {
+	base := c.base()
+	return c.raw.Add(gateway.Any, func(name string, v any) error {
+		callback(base, v.(*RawEvent))
+		return nil
+	})
+}
+
+// EventHandler passes an event on to the next event middleware, or to the event handlers
+type EventHandler func(client *Client, event string, v any)
+
+// EventMiddleware runs before the handlers of every event, e.g. to log events or ignore those of other servers:
+//
+//	func(next guildedgo.EventHandler) guildedgo.EventHandler {
+//		return func(client *guildedgo.Client, event string, v any) {
+//			log.Println("received", event)
+//			next(client, event, v)
+//		}
+//	}
+//
+// It may change the event, pass on a different one, or not call next to keep the event from the handlers.
+type EventMiddleware func(next EventHandler) EventHandler
+
+// UseEvent appends event middleware to the chain. The first middleware added is the outermost one
+func (c *Client) UseEvent(mw ...EventMiddleware) {
+	base := c.base()
+	for _, m := range mw {
+		m := m
+
+		c.dispatcher.Use(func(next gateway.Next) gateway.Next {
+			h := m(func(client *Client, event string, v any) {
+				next(event, v)
+			})
+
+			return func(name string, v any) {
+				h(base, name, v)
+			}
+		})
+	}
+}
+
+// emit calls the callbacks registered for event. payload is the raw event data, if any
+func (c *Client) emit(event string, v any, payload json.RawMessage) {
+	c.dispatcher.Dispatch(event, v, payload)
+}
+
+// post queues event to be emitted by the worker pool. Connection state events share a worker, so they stay in order
+func (c *Client) post(event string, v any) {
+	c.dispatcher.Offer(event, v)
+
+	c.pool.Submit("", func() {
+		c.emit(event, v, nil)
 	})
 }
+
+// DispatchStats is a snapshot of the event queues, see Client.DispatchStats
+type DispatchStats = gateway.PoolStats
+
+// DispatchStats reports how many events are queued, handled and dropped, and how long
+// reading from the gateway was blocked by full queues
+func (c *Client) DispatchStats() DispatchStats {
+	return c.pool.Stats()
+}
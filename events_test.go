@@ -0,0 +1,386 @@
+package guildedgo_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/itschip/guildedgo"
+)
+
+// fakeGateway sends the welcome message followed by events, then waits for the client to close
+func fakeGateway(t *testing.T, events ...string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":1,"d":{"heartbeatIntervalMs":22500}}`))
+
+		for _, e := range events {
+			conn.WriteMessage(websocket.TextMessage, []byte(e))
+		}
+
+		conn.ReadMessage()
+	}))
+}
+
+func newTestClient(srv *httptest.Server) *guildedgo.Client {
+	return guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		ServerID:     "server",
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+}
+
+func chatMessageCreated(id string, fields string) string {
+	return fmt.Sprintf(`{"op":0,"t":"ChatMessageCreated","s":"%s","d":{"serverId":"server","message":{"id":"%s","channelId":"channel"%s}}}`, id, id, fields)
+}
+
+func collect(t *testing.T, c *guildedgo.Client, event string, n int) []*guildedgo.ChatMessageCreated {
+	var mu sync.Mutex
+	var received []*guildedgo.ChatMessageCreated
+	done := make(chan struct{})
+
+	c.On(event, func(client *guildedgo.Client, v any) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		received = append(received, v.(*guildedgo.ChatMessageCreated))
+		if len(received) == n {
+			close(done)
+		}
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for events")
+		t.FailNow()
+	}
+
+	return received
+}
+
+func TestEventsDoNotShareState(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"first","isPrivate":true,"replyMessageIds":["0"],"mentions":{"everyone":true}`),
+		chatMessageCreated("2", `,"content":"second"`),
+	)
+	defer srv.Close()
+
+	received := collect(t, newTestClient(srv), "ChatMessageCreated", 2)
+
+	first, second := received[0], received[1]
+	if first == second {
+		t.Error("expected every event to be a new value")
+	}
+
+	if first.Message.Content != "first" || !first.Message.IsPrivate || !first.Message.Mentions.Everyone {
+		t.Errorf("the first event was changed by the second: %+v", first.Message)
+	}
+
+	if second.Message.IsPrivate || second.Message.ReplyMessageIds != nil || second.Message.Mentions.Everyone {
+		t.Errorf("fields of the first event leaked into the second: %+v", second.Message)
+	}
+}
+
+func TestEventsCanBeKeptByHandlers(t *testing.T) {
+	const n = 50
+
+	events := make([]string, n)
+	for i := range events {
+		events[i] = chatMessageCreated(fmt.Sprint(i), fmt.Sprintf(`,"content":"%d"`, i))
+	}
+
+	srv := fakeGateway(t, events...)
+	defer srv.Close()
+
+	received := collect(t, newTestClient(srv), "ChatMessageCreated", n)
+
+	for i, e := range received {
+		if e.Message.ID != fmt.Sprint(i) || e.Message.Content != fmt.Sprint(i) {
+			t.Errorf("event %d was overwritten: %+v", i, e.Message)
+		}
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	srv := fakeGateway(t,
+		`{"op":0,"t":"ForumTopicLocked","s":"1","d":{"serverId":"server","forumTopic":{"id":5,"title":"locked"}}}`,
+		chatMessageCreated("2", `,"content":"hello"`),
+	)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	locked := make(chan *guildedgo.ForumTopicLocked, 1)
+	guildedgo.OnEvent(c, func(client *guildedgo.Client, e *guildedgo.ForumTopicLocked) {
+		locked <- e
+	})
+
+	received := collect(t, c, guildedgo.EventName[guildedgo.ChatMessageCreated](), 1)
+	if received[0].Message.Content != "hello" {
+		t.Errorf("expected hello, got %q", received[0].Message.Content)
+	}
+
+	e := <-locked
+	if e.ServerID != "server" || e.ForumTopic.ID != 5 || e.ForumTopic.Title != "locked" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestEventName(t *testing.T) {
+	if name := guildedgo.EventName[guildedgo.CalendarEventRsvpManyUpdated](); name != "CalendarEventRsvpManyUpdated" {
+		t.Errorf("expected CalendarEventRsvpManyUpdated, got %q", name)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a type that is not an event")
+		}
+	}()
+
+	guildedgo.EventName[guildedgo.ForumTopic]()
+}
+
+func TestHandlerPanicsAreReported(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!panic"`),
+		chatMessageCreated("2", `,"content":"after"`),
+	)
+	defer srv.Close()
+
+	errs := make(chan *guildedgo.HandlerError, 1)
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+		OnHandlerError: func(err *guildedgo.HandlerError) {
+			errs <- err
+		},
+	})
+
+	c.CommandService.AddCommand(&guildedgo.Command{
+		CommandName: "!panic",
+		Action: func(client *guildedgo.Client, v *guildedgo.ChatMessageCreated) {
+			panic("boom")
+		},
+	})
+
+	received := collect(t, c, "ChatMessageCreated", 2)
+	if received[1].Message.Content != "after" {
+		t.Errorf("expected the event after the panic to be handled, got %+v", received[1].Message)
+	}
+
+	select {
+	case err := <-errs:
+		if err.Event != "ChatMessageCreated" || err.Recovered != "boom" || !strings.Contains(string(err.Payload), "!panic") {
+			t.Errorf("unexpected handler error: %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the handler error")
+	}
+}
+
+func TestEventMiddleware(t *testing.T) {
+	srv := fakeGateway(t,
+		`{"op":0,"t":"ChatMessageCreated","s":"1","d":{"serverId":"other","message":{"id":"1","channelId":"channel"}}}`,
+		chatMessageCreated("2", `,"content":"mine"`),
+	)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	// Only handle events of the client's server
+	c.UseEvent(func(next guildedgo.EventHandler) guildedgo.EventHandler {
+		return func(client *guildedgo.Client, event string, v any) {
+			e, ok := v.(*guildedgo.ChatMessageCreated)
+			if ok && e.ServerID != client.ServerID {
+				return
+			}
+
+			next(client, event, v)
+		}
+	})
+
+	received := collect(t, c, "ChatMessageCreated", 1)
+	if received[0].Message.ID != "2" {
+		t.Errorf("expected the event of the other server to be ignored, got %+v", received[0].Message)
+	}
+}
+
+func TestRawAndUnknownEvents(t *testing.T) {
+	srv := fakeGateway(t,
+		`{"op":0,"t":"SomeNewEvent","s":"1","d":{"serverId":"server","value":1}}`,
+		chatMessageCreated("2", ""),
+	)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	raw := make(chan *guildedgo.RawEvent, 2)
+	c.OnRaw(func(client *guildedgo.Client, e *guildedgo.RawEvent) {
+		raw <- e
+	})
+
+	unknown := make(chan any, 1)
+	c.On("SomeNewEvent", func(client *guildedgo.Client, v any) {
+		unknown <- v
+	})
+
+	collect(t, c, "ChatMessageCreated", 1)
+
+	select {
+	case v := <-unknown:
+		e, ok := v.(*guildedgo.RawEvent)
+		if !ok || e.T != "SomeNewEvent" || string(e.Data) != `{"serverId":"server","value":1}` {
+			t.Errorf("expected the unknown event as a raw event, got %#v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the unknown event")
+	}
+
+	seen := make(map[string]bool)
+	for len(seen) < 2 {
+		select {
+		case e := <-raw:
+			seen[e.T+" "+e.S] = e.OP == 0
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for raw events, got %v", seen)
+			t.FailNow()
+		}
+	}
+
+	if !seen["SomeNewEvent 1"] || !seen["ChatMessageCreated 2"] {
+		t.Errorf("expected both events to be passed to OnRaw, got %v", seen)
+	}
+}
+
+func TestWaitForAndCollect(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"hello"`),
+		chatMessageCreated("2", `,"content":"yes"`),
+		chatMessageCreated("3", `,"content":"no"`),
+	)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collector := c.Collect("ChatMessageCreated", guildedgo.CollectOptions{
+		Until: func(v any) bool {
+			return v.(*guildedgo.ChatMessageCreated).Message.Content == "no"
+		},
+	})
+
+	answer := make(chan *guildedgo.ChatMessageCreated, 1)
+	go func() {
+		e, err := guildedgo.WaitForEvent(ctx, c, func(e *guildedgo.ChatMessageCreated) bool {
+			return e.Message.Content == "yes" || e.Message.Content == "no"
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		answer <- e
+	}()
+
+	// Let WaitForEvent subscribe before the events arrive
+	time.Sleep(10 * time.Millisecond)
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	if e := <-answer; e == nil || e.Message.Content != "yes" {
+		t.Errorf("expected the first answer, got %+v", e)
+	}
+
+	events, err := collector.Wait(ctx)
+	if err != nil || len(events) != 3 || collector.Reason() != guildedgo.CollectorCondition {
+		t.Errorf("expected 3 events until the stop condition, got %d, %v and reason %d", len(events), err, collector.Reason())
+	}
+}
+
+func TestWaitForInHandler(t *testing.T) {
+	asked := make(chan struct{})
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":1,"d":{"heartbeatIntervalMs":22500}}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(chatMessageCreated("1", `,"content":"!delete","createdBy":"user"`)))
+
+		// Answer once the handler is about to wait, in the same channel
+		<-asked
+		time.Sleep(50 * time.Millisecond)
+		conn.WriteMessage(websocket.TextMessage, []byte(chatMessageCreated("2", `,"content":"yes","createdBy":"user"`)))
+
+		conn.ReadMessage()
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	answers := make(chan *guildedgo.ChatMessageCreated, 1)
+	c.Command("!delete", func(client *guildedgo.Client, v *guildedgo.ChatMessageCreated) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		close(asked)
+
+		answer, err := guildedgo.WaitForEvent(ctx, client, func(e *guildedgo.ChatMessageCreated) bool {
+			return e.Message.CreatedBy == v.Message.CreatedBy && e.Message.ChannelID == v.Message.ChannelID
+		})
+		if err != nil {
+			t.Errorf("expected the answer before the timeout, got %v", err)
+		}
+
+		answers <- answer
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case answer := <-answers:
+		if answer == nil || answer.Message.Content != "yes" {
+			t.Errorf("expected the answer, got %+v", answer)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the handler")
+	}
+}
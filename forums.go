@@ -1,7 +1,6 @@
 package guildedgo
 
 import (
-	"errors"
 	"fmt"
 )
 
@@ -161,7 +160,7 @@ func (service *forumService) CreateForumTopic(channelId string, forumTopicObject
 	var forumTopic ForumTopic
 	err := service.client.PostRequestV2(endpoint, &forumTopicObject, &forumTopic)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to create new forum topic. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to create new forum topic. Error: \n%w", err)
 	}
 
 	return &forumTopic, nil
@@ -174,7 +173,7 @@ func (service *forumService) GetForumTopics(channelId string) (*[]ForumTopicSumm
 
 	err := service.client.GetRequestV2(endpoint, &forumTopicSummary)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to get forum topics. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to get forum topics. Error: \n%w", err)
 	}
 
 	return &forumTopicSummary, nil
@@ -187,7 +186,7 @@ func (service *forumService) GetForumTopic(channelId string, forumTopicId int) (
 
 	err := service.client.GetRequestV2(endpoint, &forumTopic)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to get forum topic. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to get forum topic. Error: \n%w", err)
 	}
 
 	return &forumTopic, nil
@@ -200,7 +199,7 @@ func (service *forumService) UpdateForumTopic(channelId string, forumTopicId int
 
 	err := service.client.PatchRequest(endpoint, &topicObject, &forumTopic)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to update forum topic. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to update forum topic. Error: \n%w", err)
 	}
 
 	return &forumTopic, nil
@@ -211,7 +210,7 @@ func (service *forumService) DeleteForumTopic(channelId string, forumTopicId int
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to delete forum topic. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to delete forum topic. Error: \n%w", err)
 	}
 
 	return nil
@@ -222,7 +221,7 @@ func (service *forumService) PinForumTopic(channelId string, forumTopicId int) e
 
 	_, err := service.client.PutRequest(endpoint, nil)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to pin forum topic. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to pin forum topic. Error: \n%w", err)
 	}
 
 	return nil
@@ -233,7 +232,7 @@ func (service *forumService) UnpinForumTopic(channelId string, forumTopicId int)
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to unpin forum topic. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to unpin forum topic. Error: \n%w", err)
 	}
 
 	return nil
@@ -244,7 +243,7 @@ func (service *forumService) LockForumTopic(channelId string, forumTopicId int)
 
 	_, err := service.client.PutRequest(endpoint, nil)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to lock forum topic. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to lock forum topic. Error: \n%w", err)
 	}
 
 	return nil
@@ -255,7 +254,7 @@ func (service *forumService) UnlockForumTopic(channelId string, forumTopicId int
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to unlock forum topic. Error: \n%v", err.Error()))
+		return fmt.Errorf("Failed to unlock forum topic. Error: \n%w", err)
 	}
 
 	return nil
@@ -268,7 +267,7 @@ func (service *forumService) CreateTopicComment(channelId string, forumTopicId i
 
 	err := service.client.PostRequestV2(endpoint, &forumCommentObject, &forumComment)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to create new forum topic comment. Error: \n%v", err.Error()))
+		return nil, fmt.Errorf("Failed to create new forum topic comment. Error: \n%w", err)
 	}
 
 	return &forumComment, nil
@@ -0,0 +1,21 @@
+package guildedgo
+
+type Group struct {
+	ID          string `json:"id"`
+	ServerID    string `json:"serverId"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+
+	// If set, this is the home group of the server
+	IsHome    bool   `json:"isHome,omitempty"`
+	EmoteID   int    `json:"emoteId,omitempty"`
+	IsPublic  bool   `json:"isPublic,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	CreatedBy string `json:"createdBy"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	UpdatedBy string `json:"updatedBy,omitempty"`
+
+	ArchivedAt string `json:"archivedAt,omitempty"`
+	ArchivedBy string `json:"archivedBy,omitempty"`
+}
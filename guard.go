@@ -0,0 +1,236 @@
+package guildedgo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DeniedReason is why a member can't use a command
+type DeniedReason int
+
+const (
+	// DeniedChannel means the command can't be used in the channel, see Command.AllowedChannels and Command.DeniedChannels
+	DeniedChannel DeniedReason = iota + 1
+
+	// DeniedOwnerOnly means only the owner of the server can use the command
+	DeniedOwnerOnly
+
+	// DeniedRoles means the member has none of Command.RequiredRoles
+	DeniedRoles
+
+	// DeniedPermissions means the roles of the member don't grant all of Command.RequiredPermissions
+	DeniedPermissions
+)
+
+// PermissionError is why a member can't use a command. Its message is meant for the user,
+// the router replies with it unless a DeniedHandler is set.
+type PermissionError struct {
+	Reason DeniedReason
+
+	// The roles the member needs one of, for DeniedRoles
+	Roles []int
+
+	// The permissions the member is missing, for DeniedPermissions
+	Missing []string
+}
+
+func (e *PermissionError) Error() string {
+	switch e.Reason {
+	case DeniedChannel:
+		return "This command can't be used in this channel"
+	case DeniedOwnerOnly:
+		return "Only the server owner can use this command"
+	case DeniedRoles:
+		return "You don't have a role that can use this command"
+	case DeniedPermissions:
+		return fmt.Sprintf("You are missing the permissions %s", strings.Join(e.Missing, ", "))
+	}
+
+	return "You can't use this command"
+}
+
+// DeniedHandler is called instead of a command the member who invoked it can't use.
+// ctx has no converted params. An error it returns is passed to Config.OnHandlerError
+type DeniedHandler func(ctx *CommandContext, err *PermissionError) error
+
+func replyDenied(ctx *CommandContext, err *PermissionError) error {
+	_, replyErr := ctx.Reply(err.Error())
+	return replyErr
+}
+
+// checkGuards checks if the author of ctx's message may use cmds, a command and its parents.
+// It returns a *PermissionError if they can't, or an *InternalError if the member or their roles couldn't be fetched
+func (r *commandRouter) checkGuards(ctx *CommandContext, cmds []*Command) error {
+	channelID := ctx.Event.Message.ChannelID
+
+	needsMember := false
+	for _, cmd := range cmds {
+		if len(cmd.AllowedChannels) > 0 && !slices.Contains(cmd.AllowedChannels, channelID) ||
+			slices.Contains(cmd.DeniedChannels, channelID) {
+			return &PermissionError{Reason: DeniedChannel}
+		}
+
+		needsMember = needsMember || cmd.OwnerOnly || len(cmd.RequiredRoles) > 0 || len(cmd.RequiredPermissions) > 0
+	}
+
+	if !needsMember {
+		return nil
+	}
+
+	member, err := ctx.Client.Members.GetServerMember(ctx.Event.ServerID, ctx.Event.Message.CreatedBy)
+	if err != nil {
+		return &InternalError{Err: err}
+	}
+
+	if member.IsOwner {
+		return nil
+	}
+
+	var required []string
+	for _, cmd := range cmds {
+		if cmd.OwnerOnly {
+			return &PermissionError{Reason: DeniedOwnerOnly}
+		}
+
+		if len(cmd.RequiredRoles) > 0 && !slices.ContainsFunc(cmd.RequiredRoles, func(id int) bool {
+			return slices.Contains(member.RoleIds, id)
+		}) {
+			return &PermissionError{Reason: DeniedRoles, Roles: cmd.RequiredRoles}
+		}
+
+		required = append(required, cmd.RequiredPermissions...)
+	}
+
+	if len(required) == 0 {
+		return nil
+	}
+
+	// Members have the permissions of all their roles
+	granted := make(map[string]bool)
+	for _, id := range member.RoleIds {
+		permissions, ok := r.roles.get(ctx.Event.ServerID, id)
+		if !ok {
+			role, err := ctx.Client.Roles.GetRole(ctx.Event.ServerID, id)
+			if err != nil {
+				return &InternalError{Err: err}
+			}
+
+			permissions = role.Permissions
+			r.roles.set(ctx.Event.ServerID, id, permissions)
+		}
+
+		for _, permission := range permissions {
+			granted[permission] = true
+		}
+	}
+
+	var missing []string
+	for _, permission := range required {
+		if !granted[permission] && !slices.Contains(missing, permission) {
+			missing = append(missing, permission)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &PermissionError{Reason: DeniedPermissions, Missing: missing}
+	}
+
+	return nil
+}
+
+// allowed checks the guards of cmds and calls the DeniedHandler if the member can't use them
+func (r *commandRouter) allowed(ctx *CommandContext, cmds []*Command) (bool, error) {
+	err := r.checkGuards(ctx, cmds)
+	if err == nil {
+		return true, nil
+	}
+
+	var denied *PermissionError
+	if !errors.As(err, &denied) {
+		return false, err
+	}
+
+	r.mu.RLock()
+	handler := r.denied
+	r.mu.RUnlock()
+
+	if handler == nil {
+		handler = replyDenied
+	}
+
+	return false, handler(ctx, denied)
+}
+
+func (r *commandRouter) setDeniedHandler(handler DeniedHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.denied = handler
+}
+
+// roleCache keeps the permissions of roles per server, so that guards don't fetch
+// every role of a member for every command. Role events keep it up to date
+type roleCache struct {
+	mu    sync.Mutex
+	roles map[string]map[int][]string
+}
+
+func (c *roleCache) get(serverID string, roleID int) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	permissions, ok := c.roles[serverID][roleID]
+	return permissions, ok
+}
+
+func (c *roleCache) set(serverID string, roleID int, permissions []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.roles == nil {
+		c.roles = make(map[string]map[int][]string)
+	}
+
+	if c.roles[serverID] == nil {
+		c.roles[serverID] = make(map[int][]string)
+	}
+
+	c.roles[serverID][roleID] = permissions
+}
+
+func (c *roleCache) remove(serverID string, roleID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.roles[serverID], roleID)
+}
+
+func (c *roleCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.roles = nil
+}
+
+// watchRoles updates the role cache from role events
+func (r *commandRouter) watchRoles(client *Client) {
+	OnEvent(client, func(client *Client, e *RoleCreated) {
+		r.roles.set(e.ServerID, e.Role.ID, e.Role.Permissions)
+	})
+
+	OnEvent(client, func(client *Client, e *RoleUpdated) {
+		r.roles.set(e.ServerID, e.Role.ID, e.Role.Permissions)
+	})
+
+	OnEvent(client, func(client *Client, e *RoleDeleted) {
+		r.roles.remove(e.ServerID, e.Role.ID)
+	})
+
+	// Role events may have been missed
+	OnEvent(client, func(client *Client, e *ResyncRequired) {
+		r.roles.reset()
+	})
+}
@@ -0,0 +1,216 @@
+package guildedgo_test
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo"
+)
+
+func TestCommandGuards(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!kick","createdBy":"user"`),
+		chatMessageCreated("2", `,"content":"!ban","createdBy":"user"`),
+		chatMessageCreated("3", `,"content":"!admin","createdBy":"user"`),
+		chatMessageCreated("4", `,"content":"!admin","createdBy":"owner"`),
+		chatMessageCreated("5", `,"content":"!shutdown","createdBy":"user"`),
+		chatMessageCreated("6", `,"content":"!quiet","createdBy":"user"`),
+		chatMessageCreated("7", `,"content":"!config set","createdBy":"user"`),
+	)
+	defer srv.Close()
+
+	sent := make(chan guildedgo.MessageObject, 5)
+	api := fakeAPI(t, sent)
+	defer api.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	invocations := make(chan string, 7)
+	handler := func(ctx *guildedgo.CommandContext) error {
+		invocations <- ctx.Name + " " + ctx.Message().CreatedBy
+		return nil
+	}
+
+	c.CommandService.AddCommands(&guildedgo.CommandsBuilder{
+		Commands: []guildedgo.Command{
+			{CommandName: "kick", RequiredPermissions: []string{"CanKickMembers"}, Handler: handler},
+			{CommandName: "ban", RequiredPermissions: []string{"CanKickMembers", "CanBanMembers"}, Handler: handler},
+			{CommandName: "admin", RequiredRoles: []int{3, 4}, Handler: handler},
+			{CommandName: "shutdown", OwnerOnly: true, Handler: handler},
+			{CommandName: "quiet", DeniedChannels: []string{"channel"}, Handler: handler},
+			{
+				CommandName:     "config",
+				AllowedChannels: []string{"channel"},
+				RequiredRoles:   []int{3},
+				Subcommands:     []guildedgo.Command{{CommandName: "set", Handler: handler}},
+			},
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	for _, want := range []string{
+		"You are missing the permissions CanBanMembers",
+		"You don't have a role that can use this command",
+		"Only the server owner can use this command",
+		"This command can't be used in this channel",
+		"You don't have a role that can use this command",
+	} {
+		select {
+		case msg := <-sent:
+			if msg.Content != want {
+				t.Errorf("expected %q, got %q", want, msg.Content)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for %q", want)
+			t.FailNow()
+		}
+	}
+
+	for _, want := range []string{"kick user", "admin owner"} {
+		select {
+		case got := <-invocations:
+			if got != want {
+				t.Errorf("expected %q, got %q", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for %q", want)
+			t.FailNow()
+		}
+	}
+
+	select {
+	case got := <-invocations:
+		t.Errorf("expected no other commands to run, got %q", got)
+	default:
+	}
+}
+
+func TestDeniedHandler(t *testing.T) {
+	srv := fakeGateway(t, chatMessageCreated("1", `,"content":"!shutdown","createdBy":"user"`))
+	defer srv.Close()
+
+	api := fakeAPI(t, nil)
+	defer api.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	denied := make(chan *guildedgo.PermissionError, 1)
+	c.CommandService.SetDeniedHandler(func(ctx *guildedgo.CommandContext, err *guildedgo.PermissionError) error {
+		if ctx.Command.CommandName != "shutdown" {
+			t.Errorf("unexpected command %q", ctx.Command.CommandName)
+		}
+
+		denied <- err
+		return nil
+	})
+
+	c.CommandService.AddCommand(&guildedgo.Command{
+		CommandName: "shutdown",
+		OwnerOnly:   true,
+		Handler: func(ctx *guildedgo.CommandContext) error {
+			t.Error("expected the command not to run")
+			return nil
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case err := <-denied:
+		if err.Reason != guildedgo.DeniedOwnerOnly {
+			t.Errorf("expected the command to be owner only, got %d", err.Reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the denied handler")
+	}
+}
+
+func TestCommandGuardsCacheRoles(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!kick","createdBy":"user"`),
+		chatMessageCreated("2", `,"content":"!kick","createdBy":"user"`),
+		`{"op":0,"t":"RoleUpdated","s":"3","d":{"serverId":"server","role":{"id":2,"permissions":["CanReadChats"]}}}`,
+		chatMessageCreated("4", `,"content":"!kick","createdBy":"user"`),
+	)
+	defer srv.Close()
+
+	sent := make(chan guildedgo.MessageObject, 1)
+	api := fakeAPI(t, sent)
+	defer api.Close()
+
+	// One worker keeps the role event between the commands
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+		Workers:      1,
+	})
+
+	var roleRequests atomic.Int32
+	c.Use(func(next guildedgo.Handler) guildedgo.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/roles/") {
+				roleRequests.Add(1)
+			}
+
+			return next(req)
+		}
+	})
+
+	invocations := make(chan string, 3)
+	c.CommandService.AddCommand(&guildedgo.Command{
+		CommandName:         "kick",
+		RequiredPermissions: []string{"CanKickMembers"},
+		Handler: func(ctx *guildedgo.CommandContext) error {
+			invocations <- ctx.Message().ID
+			return nil
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case msg := <-sent:
+		if msg.Content != "You are missing the permissions CanKickMembers" {
+			t.Errorf("expected the updated role to deny the command, got %q", msg.Content)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the denial")
+		t.FailNow()
+	}
+
+	if len(invocations) != 2 {
+		t.Errorf("expected the command to run twice before the role was updated, got %d", len(invocations))
+	}
+
+	if n := roleRequests.Load(); n != 2 {
+		t.Errorf("expected each role to be fetched once, got %d requests", n)
+	}
+}
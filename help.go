@@ -0,0 +1,307 @@
+package guildedgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// otherCategory lists the commands without a category
+	otherCategory = "Other"
+
+	// maxEmbedFieldValue is the longest value Guilded accepts for an embed field
+	maxEmbedFieldValue = 1024
+)
+
+// HelpCommand returns a command that lists the commands per category, "!help",
+// or describes one command, "!help config set". Hidden commands are left out.
+// Register it like any other command, its name and aliases may be changed:
+//
+//	client.CommandService.AddCommand(guildedgo.HelpCommand())
+func HelpCommand() *Command {
+	return &Command{
+		CommandName: "help",
+		Description: "Lists the commands, or describes one of them",
+		Examples:    []string{"help", "help ban"},
+		Params: []Param{
+			{Name: "command", Type: ArgString, Rest: true, Optional: true, Description: "The command to describe"},
+		},
+		Handler: help,
+	}
+}
+
+func help(ctx *CommandContext) error {
+	prefix := ctx.Client.router.helpPrefix(ctx.Prefix)
+
+	name := ctx.Text("command")
+	if name == "" {
+		_, err := ctx.ReplyEmbed(commandList(ctx.Client.router, prefix))
+		return err
+	}
+
+	node, path := ctx.Client.router.lookup(strings.Fields(name))
+	if node == nil {
+		_, err := ctx.Reply(fmt.Sprintf("There is no command named %s", name))
+		return err
+	}
+
+	_, err := ctx.ReplyEmbed(commandHelp(prefix, path, node.cmd))
+	return err
+}
+
+// helpPrefix returns the prefix to show in help texts. If help was invoked by mentioning the bot,
+// that is the first prefix passed to SetPrefixes, or the mention followed by a space if there is none
+func (r *commandRouter) helpPrefix(prefix string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.botID == "" || prefix != "<@"+r.botID+">" {
+		return prefix
+	}
+
+	if r.primary != "" {
+		return r.primary
+	}
+
+	return prefix + " "
+}
+
+// lookup finds the visible command named by all of tokens and returns it with its full name, e.g. "config set" for "cfg SET".
+// It returns nil if there is no such command, or it or one of its parents is hidden
+func (r *commandRouter) lookup(tokens []string) (*commandNode, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	node := r.root
+	names := make([]string, len(tokens))
+	for i, token := range tokens {
+		node = node.children[strings.ToLower(token)]
+		if node == nil || node.cmd.Hidden {
+			return nil, ""
+		}
+
+		names[i] = node.cmd.CommandName
+	}
+
+	if node == r.root {
+		return nil, ""
+	}
+
+	return node, strings.Join(names, " ")
+}
+
+// commandEntry is a command with the names of its parents, e.g. "config set"
+type commandEntry struct {
+	path     string
+	cmd      *Command
+	category string
+
+	// exact commands are invoked by their name without a prefix
+	exact bool
+}
+
+// commands returns the visible commands that can be invoked, sorted by name
+func (r *commandRouter) commands() []commandEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []commandEntry
+	seen := make(map[*commandNode]bool)
+
+	var walk func(node *commandNode, path string, category string)
+	walk = func(node *commandNode, path string, category string) {
+		for _, child := range node.children {
+			// Aliases point at the same node
+			if seen[child] || child.cmd.Hidden {
+				continue
+			}
+			seen[child] = true
+
+			childPath := strings.TrimSpace(path + " " + child.cmd.CommandName)
+			childCategory := child.cmd.Category
+			if childCategory == "" {
+				childCategory = category
+			}
+
+			if child.cmd.Handler != nil {
+				entries = append(entries, commandEntry{path: childPath, cmd: child.cmd, category: childCategory})
+			}
+
+			walk(child, childPath, childCategory)
+		}
+	}
+
+	walk(r.root, "", "")
+
+	for name, cmds := range r.exact {
+		for _, cmd := range cmds {
+			if !cmd.Hidden {
+				entries = append(entries, commandEntry{path: name, cmd: cmd, category: cmd.Category, exact: true})
+				break
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].path < entries[j].path
+	})
+
+	return entries
+}
+
+// commandList renders the commands per category, the categories sorted by name with Other last
+func commandList(r *commandRouter, prefix string) ChatEmbed {
+	byCategory := make(map[string][]string)
+	var categories []string
+
+	for _, e := range r.commands() {
+		category := e.category
+		if category == "" {
+			category = otherCategory
+		}
+
+		if _, ok := byCategory[category]; !ok {
+			categories = append(categories, category)
+		}
+
+		line := fmt.Sprintf("`%s%s`", prefix, e.path)
+		if e.exact {
+			line = fmt.Sprintf("`%s`", e.path)
+		}
+		if e.cmd.Description != "" {
+			line += " - " + e.cmd.Description
+		}
+
+		byCategory[category] = append(byCategory[category], line)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[j] == otherCategory {
+			return categories[i] != otherCategory
+		}
+		if categories[i] == otherCategory {
+			return false
+		}
+
+		return categories[i] < categories[j]
+	})
+
+	embed := ChatEmbed{
+		Title:  "Commands",
+		Footer: ChatEmbedFooter{Text: fmt.Sprintf("Use %shelp <command> for details", prefix)},
+	}
+
+	for _, category := range categories {
+		embed.Fields = append(embed.Fields, ChatEmbedField{
+			Name:  category,
+			Value: truncate(strings.Join(byCategory[category], "\n")),
+		})
+	}
+
+	if len(embed.Fields) == 0 {
+		embed.Description = "There are no commands"
+	}
+
+	return embed
+}
+
+// commandHelp renders the description, usage, aliases, arguments, examples and subcommands of cmd
+func commandHelp(prefix string, path string, cmd *Command) ChatEmbed {
+	embed := ChatEmbed{
+		Title:       prefix + path,
+		Description: cmd.Description,
+		Footer:      ChatEmbedFooter{Text: "<required> [optional]"},
+	}
+
+	if cmd.Handler != nil {
+		usage := strings.TrimSpace(prefix + path + " " + commandUsage(cmd))
+		embed.Fields = append(embed.Fields, ChatEmbedField{Name: "Usage", Value: truncate("`" + usage + "`")})
+	}
+
+	if len(cmd.Aliases) > 0 {
+		embed.Fields = append(embed.Fields, ChatEmbedField{Name: "Aliases", Value: truncate(strings.Join(cmd.Aliases, ", "))})
+	}
+
+	var params []string
+	for _, p := range cmd.Params {
+		if p.Description != "" {
+			params = append(params, fmt.Sprintf("`%s` (%s) - %s", p.Name, p.Type.Name(), p.Description))
+		}
+	}
+
+	if len(params) > 0 {
+		embed.Fields = append(embed.Fields, ChatEmbedField{Name: "Arguments", Value: truncate(strings.Join(params, "\n"))})
+	}
+
+	if len(cmd.Examples) > 0 {
+		examples := make([]string, len(cmd.Examples))
+		for i, example := range cmd.Examples {
+			examples[i] = "`" + prefix + example + "`"
+		}
+
+		embed.Fields = append(embed.Fields, ChatEmbedField{Name: "Examples", Value: truncate(strings.Join(examples, "\n"))})
+	}
+
+	var subcommands []string
+	for _, sub := range cmd.Subcommands {
+		if sub.Hidden {
+			continue
+		}
+
+		line := fmt.Sprintf("`%s%s %s`", prefix, path, sub.CommandName)
+		if sub.Description != "" {
+			line += " - " + sub.Description
+		}
+
+		subcommands = append(subcommands, line)
+	}
+
+	if len(subcommands) > 0 {
+		embed.Fields = append(embed.Fields, ChatEmbedField{Name: "Subcommands", Value: truncate(strings.Join(subcommands, "\n"))})
+	}
+
+	return embed
+}
+
+// commandUsage returns Command.Usage, or describes the params, e.g. <member> [reason...] [--in=channel]
+func commandUsage(cmd *Command) string {
+	if cmd.Usage != "" {
+		return cmd.Usage
+	}
+
+	usage := make([]string, len(cmd.Params))
+	for i, p := range cmd.Params {
+		arg := p.Name
+		if p.Rest {
+			arg += "..."
+		}
+		if p.Flag {
+			arg = fmt.Sprintf("--%s=%s", p.Name, p.Type.Name())
+		}
+
+		if p.Optional {
+			usage[i] = "[" + arg + "]"
+		} else if p.Flag {
+			usage[i] = arg
+		} else {
+			usage[i] = "<" + arg + ">"
+		}
+	}
+
+	return strings.Join(usage, " ")
+}
+
+func truncate(s string) string {
+	if len(s) <= maxEmbedFieldValue {
+		return s
+	}
+
+	// Cut at a line break so that no command is cut in half
+	s = s[:maxEmbedFieldValue-len("\n...")]
+	if i := strings.LastIndexByte(s, '\n'); i > 0 {
+		s = s[:i]
+	}
+
+	return s + "\n..."
+}
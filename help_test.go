@@ -0,0 +1,124 @@
+package guildedgo_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo"
+)
+
+func TestHelpCommand(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!help"`),
+		chatMessageCreated("2", `,"content":"!help CFG set"`),
+		chatMessageCreated("3", `,"content":"!help secret"`),
+		chatMessageCreated("4", `,"content":"<@bot> help mute"`),
+	)
+	defer srv.Close()
+
+	sent := make(chan guildedgo.MessageObject, 4)
+	api := fakeAPI(t, sent)
+	defer api.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	c.CommandService.SetBotID("bot")
+
+	noop := func(ctx *guildedgo.CommandContext) error { return nil }
+
+	c.CommandService.AddCommand(guildedgo.HelpCommand())
+	c.CommandService.AddCommands(&guildedgo.CommandsBuilder{
+		Commands: []guildedgo.Command{
+			{
+				CommandName: "mute",
+				Description: "Mutes a member",
+				Category:    "Moderation",
+				Params: []guildedgo.Param{
+					{Name: "member", Type: guildedgo.ArgMember, Description: "Who to mute"},
+					{Name: "reason", Type: guildedgo.ArgString, Rest: true, Optional: true},
+					{Name: "in", Type: guildedgo.ArgChannel, Flag: true, Optional: true},
+				},
+				Handler: noop,
+			},
+			{
+				CommandName: "config",
+				Aliases:     []string{"cfg"},
+				Category:    "Settings",
+				Subcommands: []guildedgo.Command{
+					{
+						CommandName: "set",
+						Description: "Changes a setting",
+						Aliases:     []string{"s"},
+						Examples:    []string{"config set prefix ?"},
+						Params: []guildedgo.Param{
+							{Name: "key", Type: guildedgo.ArgEnum("prefix", "language")},
+							{Name: "value", Type: guildedgo.ArgString},
+						},
+						Handler: noop,
+					},
+				},
+			},
+			{CommandName: "secret", Hidden: true, Handler: noop},
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	var msgs []guildedgo.MessageObject
+	for len(msgs) < 4 {
+		select {
+		case msg := <-sent:
+			msgs = append(msgs, msg)
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for replies, got %d", len(msgs))
+			t.FailNow()
+		}
+	}
+
+	list := msgs[0].Embeds[0]
+	want := []guildedgo.ChatEmbedField{
+		{Name: "Moderation", Value: "`!mute` - Mutes a member"},
+		{Name: "Settings", Value: "`!config set` - Changes a setting"},
+		{Name: "Other", Value: "`!help` - Lists the commands, or describes one of them"},
+	}
+	if list.Title != "Commands" || len(list.Fields) != len(want) {
+		t.Errorf("unexpected command list %+v", list)
+		t.FailNow()
+	}
+	for i, field := range list.Fields {
+		if field != want[i] {
+			t.Errorf("expected field %+v, got %+v", want[i], field)
+		}
+	}
+
+	detail := msgs[1].Embeds[0]
+	fields := make(map[string]string)
+	for _, field := range detail.Fields {
+		fields[field.Name] = field.Value
+	}
+	if detail.Title != "!config set" || detail.Description != "Changes a setting" ||
+		fields["Usage"] != "`!config set <key> <value>`" || fields["Aliases"] != "s" ||
+		fields["Examples"] != "`!config set prefix ?`" {
+		t.Errorf("unexpected command help %+v", detail)
+	}
+
+	if msgs[2].Content != "There is no command named secret" {
+		t.Errorf("expected hidden commands to be left out, got %q", msgs[2].Content)
+	}
+
+	// Mentioning the bot isn't a prefix that reads well in help texts
+	mute := msgs[3].Embeds[0]
+	if mute.Title != "!mute" || mute.Fields[0].Value != "`!mute <member> [reason...] [--in=channel]`" {
+		t.Errorf("expected the command prefix in help invoked by mention, got %+v", mute)
+	}
+}
@@ -0,0 +1,115 @@
+// Package command splits chat messages into command arguments for the guildedgo command router
+package command
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrUnterminatedQuote is returned by Tokenize when a quoted argument isn't closed
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// Tokenize splits s at whitespace. Text in double or single quotes is kept together,
+// e.g. `ban "some user" --reason="too many links"` gives ban, some user and --reason=too many links.
+// Quotes only start an argument at its beginning or after =, so apostrophes in words like don't are kept.
+// A backslash keeps the next character from being interpreted, e.g. \" or \ followed by a space.
+func Tokenize(s string) ([]string, error) {
+	var tokens []string
+	var token strings.Builder
+
+	inToken := false
+	var quote, prev rune
+	escaped := false
+
+	for _, r := range s {
+		last := prev
+		prev = r
+
+		switch {
+		case escaped:
+			token.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inToken = true
+		case quote != 0:
+			if closes(quote, r) {
+				quote = 0
+			} else {
+				token.WriteRune(r)
+			}
+		case opens(r) && (!inToken || last == '='):
+			quote = r
+			inToken = true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+
+	if escaped {
+		// A trailing backslash stands for itself
+		token.WriteRune('\\')
+	}
+
+	if inToken {
+		tokens = append(tokens, token.String())
+	}
+
+	return tokens, nil
+}
+
+// opens reports whether r starts a quoted argument. Phones often replace " with typographic quotes
+func opens(r rune) bool {
+	return r == '"' || r == '\'' || r == '“'
+}
+
+func closes(quote, r rune) bool {
+	if quote == '“' {
+		return r == '”' || r == '"'
+	}
+
+	return r == quote
+}
+
+// Args are the arguments of a command, split into positional arguments and flags
+type Args struct {
+	Positional []string
+
+	// Flags maps the names of --name=value arguments to their values. Flags without a value,
+	// e.g. --silent, have an empty value
+	Flags map[string]string
+}
+
+// Parse sorts tokens into positional arguments and flags. Tokens after "--" are always positional
+func Parse(tokens []string) Args {
+	args := Args{Flags: make(map[string]string)}
+
+	for i, token := range tokens {
+		if token == "--" {
+			args.Positional = append(args.Positional, tokens[i+1:]...)
+			break
+		}
+
+		if len(token) > 2 && strings.HasPrefix(token, "--") {
+			name, value, _ := strings.Cut(token[2:], "=")
+			args.Flags[name] = value
+			continue
+		}
+
+		args.Positional = append(args.Positional, token)
+	}
+
+	return args
+}
@@ -0,0 +1,53 @@
+package command_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/itschip/guildedgo/internal/command"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		``:                                   nil,
+		`  ban   user  `:                     {"ban", "user"},
+		`ban "some user" spamming`:           {"ban", "some user", "spamming"},
+		`say 'single "quoted"'`:              {"say", `single "quoted"`},
+		`ban user --reason="too many links"`: {"ban", "user", "--reason=too many links"},
+		`say don't stop`:                     {"say", "don't", "stop"},
+		`say “smart quotes”`:                 {"say", "smart quotes"},
+		`say \"escaped\" one\ token`:         {"say", `"escaped"`, "one token"},
+		`say "" empty`:                       {"say", "", "empty"},
+		"multi\nline\ttabs":                  {"multi", "line", "tabs"},
+		`trailing\`:                          {`trailing\`},
+	}
+
+	for s, want := range tests {
+		got, err := command.Tokenize(s)
+		if err != nil {
+			t.Errorf("Tokenize(%q) failed: %s", s, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", s, got, want)
+		}
+	}
+
+	_, err := command.Tokenize(`say "unterminated`)
+	if err != command.ErrUnterminatedQuote {
+		t.Errorf("expected an unterminated quote error, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	args := command.Parse([]string{"user", "--reason=spam", "--silent", "-5", "--", "--literal"})
+
+	if !reflect.DeepEqual(args.Positional, []string{"user", "-5", "--literal"}) {
+		t.Errorf("unexpected positional arguments %q", args.Positional)
+	}
+
+	if !reflect.DeepEqual(args.Flags, map[string]string{"reason": "spam", "silent": ""}) {
+		t.Errorf("unexpected flags %q", args.Flags)
+	}
+}
@@ -1,18 +1,15 @@
 package internal
 
 import (
-	"log"
 	"os"
 
 	"github.com/joho/godotenv"
 )
 
+// GetEnv returns the environment variable key, after loading .env if there is one.
+// Variables that are already set take precedence over those in .env
 func GetEnv(key string) string {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Println(err.Error())
-		log.Fatal("Error loading .env file")
-	}
+	_ = godotenv.Load(".env")
 
 	return os.Getenv(key)
 }
@@ -0,0 +1,214 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StopReason is why a Collector stopped
+type StopReason int
+
+const (
+	// StopLimit means the collector gathered CollectorConfig.Max events
+	StopLimit StopReason = iota + 1
+
+	// StopTimeout means CollectorConfig.Timeout passed
+	StopTimeout
+
+	// StopCondition means CollectorConfig.Until returned true
+	StopCondition
+
+	// StopCancelled means Stop was called, or the context passed to Wait was done
+	StopCancelled
+)
+
+type CollectorConfig struct {
+	// Filter decides which events are collected, every event is if it's nil
+	Filter func(v any) bool
+
+	// Max stops the collector after this many events, zero for no limit
+	Max int
+
+	// Timeout stops the collector after this long, zero for no timeout
+	Timeout time.Duration
+
+	// Until is called with every collected event, the collector stops once it returns true
+	Until func(v any) bool
+}
+
+// Collector gathers the events offered under a name until it is stopped
+type Collector struct {
+	cfg   CollectorConfig
+	d     *Dispatcher
+	name  string
+	timer *time.Timer
+	done  chan struct{}
+
+	mu     sync.Mutex
+	events []any
+	reason StopReason
+}
+
+// Collect starts gathering the events offered under name, or every event if name is Any.
+// Collectors receive events from Offer, not from Dispatch, see Offer.
+func (d *Dispatcher) Collect(name string, cfg CollectorConfig) *Collector {
+	c := &Collector{cfg: cfg, d: d, name: name, done: make(chan struct{})}
+
+	// Offer waits for the lock, so it doesn't see the collector half set up
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d.mu.Lock()
+	d.collectors[name] = append(d.collectors[name], c)
+	d.mu.Unlock()
+
+	if cfg.Timeout > 0 {
+		c.timer = time.AfterFunc(cfg.Timeout, func() {
+			c.stop(StopTimeout)
+		})
+	}
+
+	return c
+}
+
+func (c *Collector) collect(v any) {
+	if c.cfg.Filter != nil && !c.cfg.Filter(v) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reason != 0 {
+		return
+	}
+
+	c.events = append(c.events, v)
+
+	if c.cfg.Max > 0 && len(c.events) >= c.cfg.Max {
+		c.stopLocked(StopLimit)
+	} else if c.cfg.Until != nil && c.cfg.Until(v) {
+		c.stopLocked(StopCondition)
+	}
+}
+
+// Stop stops collecting. Stopping a stopped collector is a no-op
+func (c *Collector) Stop() {
+	c.stop(StopCancelled)
+}
+
+func (c *Collector) stop(reason StopReason) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopLocked(reason)
+}
+
+func (c *Collector) stopLocked(reason StopReason) {
+	if c.reason != 0 {
+		return
+	}
+
+	c.reason = reason
+	c.d.unwatch(c)
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+
+	close(c.done)
+}
+
+// Done is closed when the collector stops
+func (c *Collector) Done() <-chan struct{} {
+	return c.done
+}
+
+// Reason returns why the collector stopped, zero while it is running
+func (c *Collector) Reason() StopReason {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.reason
+}
+
+// Events returns the events collected so far, in the order they were dispatched
+func (c *Collector) Events() []any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]any(nil), c.events...)
+}
+
+// Wait blocks until the collector stops and returns the collected events.
+// If ctx is done first, the collector is stopped and the events collected so far are returned with ctx's error.
+func (c *Collector) Wait(ctx context.Context) ([]any, error) {
+	select {
+	case <-c.done:
+		return c.Events(), nil
+	case <-ctx.Done():
+		c.Stop()
+		return c.Events(), ctx.Err()
+	}
+}
+
+// Watched reports whether a collector is gathering events named name, so that they should be offered
+func (d *Dispatcher) Watched(name string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return len(d.collectors[name]) > 0 || len(d.collectors[Any]) > 0
+}
+
+// Offer passes the event to the collectors for name, and those for Any. It doesn't block on handlers,
+// so it is called as soon as an event is received, before it is queued to be dispatched.
+// That way a handler can wait for the next event of its own channel, which is queued behind it.
+// Panics in Filter and Until are reported like those in handlers.
+func (d *Dispatcher) Offer(name string, v any) {
+	d.mu.RLock()
+	collectors := d.collectors[name]
+	wildcard := d.collectors[Any]
+	d.mu.RUnlock()
+
+	for _, c := range append(collectors[:len(collectors):len(collectors)], wildcard...) {
+		c := c
+		d.invoke(func(name string, v any) error {
+			c.collect(v)
+			return nil
+		}, name, v, nil)
+	}
+}
+
+func (d *Dispatcher) unwatch(c *Collector) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	collectors := d.collectors[c.name]
+	for i, other := range collectors {
+		if other == c {
+			// Copy instead of removing in place, Offer may still be iterating the old slice
+			collectors = append(collectors[:i:i], collectors[i+1:]...)
+			break
+		}
+	}
+
+	if len(collectors) == 0 {
+		delete(d.collectors, c.name)
+		return
+	}
+
+	d.collectors[c.name] = collectors
+}
+
+// Wait blocks until an event is offered under name that predicate accepts, and returns it.
+// predicate may be nil to accept the next event. It returns ctx's error if ctx is done first.
+func (d *Dispatcher) Wait(ctx context.Context, name string, predicate func(v any) bool) (any, error) {
+	c := d.Collect(name, CollectorConfig{Filter: predicate, Max: 1})
+
+	events, err := c.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return events[0], nil
+}
@@ -0,0 +1,130 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo/internal/gateway"
+)
+
+func TestCollectorLimit(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	c := d.Collect("Event", gateway.CollectorConfig{
+		Filter: func(v any) bool { return v.(int)%2 == 0 },
+		Max:    2,
+	})
+
+	for i := 0; i < 10; i++ {
+		d.Offer("Event", i)
+	}
+
+	events, err := c.Wait(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(events, []any{0, 2}) || c.Reason() != gateway.StopLimit {
+		t.Errorf("expected 0 and 2 to be collected up to the limit, got %v and reason %d", events, c.Reason())
+	}
+}
+
+func TestCollectorUntil(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	c := d.Collect("Event", gateway.CollectorConfig{
+		Until: func(v any) bool { return v == "stop" },
+	})
+
+	d.Offer("Event", "a")
+	d.Offer("Event", "stop")
+	d.Offer("Event", "b")
+
+	<-c.Done()
+	if events := c.Events(); !reflect.DeepEqual(events, []any{"a", "stop"}) || c.Reason() != gateway.StopCondition {
+		t.Errorf("expected a and stop to be collected, got %v and reason %d", events, c.Reason())
+	}
+}
+
+func TestCollectorTimeout(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	c := d.Collect("Event", gateway.CollectorConfig{Timeout: 10 * time.Millisecond})
+	d.Offer("Event", 1)
+
+	events, err := c.Wait(context.Background())
+	if err != nil || !reflect.DeepEqual(events, []any{1}) || c.Reason() != gateway.StopTimeout {
+		t.Errorf("expected 1 to be collected until the timeout, got %v, %v and reason %d", events, err, c.Reason())
+	}
+
+	d.Offer("Event", 2)
+	if len(c.Events()) != 1 {
+		t.Error("expected no events to be collected after the collector stopped")
+	}
+}
+
+func TestDispatcherWait(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	stop := make(chan struct{})
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+
+		// Keep offering, Wait may subscribe after the first events
+		for i := 0; ; i = (i + 1) % 5 {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			d.Offer("Event", i)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	v, err := d.Wait(context.Background(), "Event", func(v any) bool { return v.(int) == 3 })
+	if err != nil || v != 3 {
+		t.Errorf("expected 3, got %v and %v", v, err)
+	}
+
+	close(stop)
+	<-dispatched
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = d.Wait(ctx, "Event", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
+
+func TestOfferRecoversFromPanickingFilters(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var reported *gateway.HandlerError
+	d.OnError = func(err *gateway.HandlerError) {
+		reported = err
+	}
+
+	c := d.Collect("Event", gateway.CollectorConfig{
+		Filter: func(v any) bool { panic("filter") },
+	})
+	defer c.Stop()
+
+	d.Offer("Event", 1)
+	if reported == nil || reported.Recovered != "filter" {
+		t.Errorf("expected the panic to be reported, got %v", reported)
+	}
+
+	// Handlers get events from Dispatch, collectors from Offer
+	d.Dispatch("Event", 2, nil)
+	if len(c.Events()) != 0 {
+		t.Errorf("expected dispatched events not to be collected, got %v", c.Events())
+	}
+}
@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Any is the name handlers are registered under to receive every event
+const Any = "*"
+
+// Handler is called with the name and decoded value of an event
+type Handler func(name string, v any) error
+
+// HandlerError is a panic in a handler, or an error it returned
+type HandlerError struct {
+	// The name of the event the handler was called for
+	Event string
+
+	// The decoded event
+	Value any
+
+	// The raw event data, nil for events that didn't come from the gateway
+	Payload json.RawMessage
+
+	// The error returned by the handler, or an error describing the panic
+	Err error
+
+	// The value the handler panicked with, nil if it returned an error
+	Recovered any
+
+	// The stack trace of the panic
+	Stack []byte
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler for %s: %s", e.Event, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// Subscription is a handler registered with a Dispatcher
+type Subscription struct {
+	d       *Dispatcher
+	name    string
+	h       Handler
+	once    bool
+	removed atomic.Bool
+}
+
+// Remove unregisters the handler. It is not called for events dispatched afterwards,
+// removing a subscription more than once is a no-op.
+func (s *Subscription) Remove() {
+	if s.removed.Swap(true) {
+		return
+	}
+
+	s.d.remove(s)
+}
+
+// Next passes an event on to the next middleware, or to the handlers
+type Next func(name string, v any)
+
+// Middleware wraps the dispatching of every event. It may change the event, pass on a different one,
+// or not call next at all to keep the event from the handlers.
+type Middleware func(next Next) Next
+
+// Dispatcher holds the handlers registered per event name
+type Dispatcher struct {
+	// OnError is called when a handler panics or returns an error. The error is logged if it's nil
+	OnError func(err *HandlerError)
+
+	// Logger receives handler errors when OnError is nil, slog.Default() if nil
+	Logger *slog.Logger
+
+	mu         sync.RWMutex
+	handlers   map[string][]*Subscription
+	collectors map[string][]*Collector
+	middleware []Middleware
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers:   make(map[string][]*Subscription),
+		collectors: make(map[string][]*Collector),
+	}
+}
+
+// Add registers h for events named name, or for every event if name is Any
+func (d *Dispatcher) Add(name string, h Handler) *Subscription {
+	return d.add(name, h, false)
+}
+
+// Once registers h for the next event named name. It is removed before it is called
+func (d *Dispatcher) Once(name string, h Handler) *Subscription {
+	return d.add(name, h, true)
+}
+
+func (d *Dispatcher) add(name string, h Handler, once bool) *Subscription {
+	s := &Subscription{d: d, name: name, h: h, once: once}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.handlers[name] = append(d.handlers[name], s)
+	return s
+}
+
+func (d *Dispatcher) remove(s *Subscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	handlers := d.handlers[s.name]
+	for i, h := range handlers {
+		if h == s {
+			// Copy instead of removing in place, Dispatch may still be iterating the old slice
+			handlers = append(handlers[:i:i], handlers[i+1:]...)
+			break
+		}
+	}
+
+	if len(handlers) == 0 {
+		delete(d.handlers, s.name)
+		return
+	}
+
+	d.handlers[s.name] = handlers
+}
+
+// Use appends middleware to the chain. The first middleware added is the outermost one
+func (d *Dispatcher) Use(mw ...Middleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.middleware = append(d.middleware, mw...)
+}
+
+// Dispatch passes the event through the middleware, then calls the handlers for name,
+// followed by the handlers for Any, with v.
+// payload is the raw event v was decoded from, it is only used to report handler errors.
+// Handlers may register other handlers, those are called from the next event on.
+// A panicking handler doesn't keep the others from being called.
+func (d *Dispatcher) Dispatch(name string, v any, payload json.RawMessage) {
+	d.mu.RLock()
+	middleware := d.middleware
+	d.mu.RUnlock()
+
+	next := Next(func(name string, v any) {
+		d.dispatch(name, v, payload)
+	})
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+
+	// Panics in middleware are reported like those in handlers
+	d.invoke(func(name string, v any) error {
+		next(name, v)
+		return nil
+	}, name, v, payload)
+}
+
+func (d *Dispatcher) dispatch(name string, v any, payload json.RawMessage) {
+	d.mu.RLock()
+	handlers := d.handlers[name]
+	wildcard := d.handlers[Any]
+	d.mu.RUnlock()
+
+	d.call(handlers, name, v, payload)
+	d.call(wildcard, name, v, payload)
+}
+
+func (d *Dispatcher) call(handlers []*Subscription, name string, v any, payload json.RawMessage) {
+	for _, s := range handlers {
+		if s.once {
+			if s.removed.Swap(true) {
+				continue
+			}
+
+			d.remove(s)
+		} else if s.removed.Load() {
+			continue
+		}
+
+		d.invoke(s.h, name, v, payload)
+	}
+}
+
+func (d *Dispatcher) invoke(h Handler, name string, v any, payload json.RawMessage) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			d.report(&HandlerError{
+				Event:     name,
+				Value:     v,
+				Payload:   payload,
+				Err:       fmt.Errorf("panic: %v", r),
+				Recovered: r,
+				Stack:     debug.Stack(),
+			})
+		}
+	}()
+
+	err := h(name, v)
+	if err != nil {
+		d.report(&HandlerError{
+			Event:   name,
+			Value:   v,
+			Payload: payload,
+			Err:     err,
+		})
+	}
+}
+
+func (d *Dispatcher) report(err *HandlerError) {
+	if d.OnError == nil {
+		logger := d.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		attrs := []any{"event", err.Event, "error", err.Err}
+		if err.Stack != nil {
+			attrs = append(attrs, "stack", string(err.Stack))
+		}
+
+		logger.Error("Event handler failed", attrs...)
+		return
+	}
+
+	d.OnError(err)
+}
@@ -0,0 +1,169 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/itschip/guildedgo/internal/gateway"
+)
+
+func TestDispatcherRemove(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var calls int
+	s := d.Add("Event", func(name string, v any) error {
+		calls++
+		return nil
+	})
+
+	d.Dispatch("Event", nil, nil)
+	s.Remove()
+	s.Remove()
+	d.Dispatch("Event", nil, nil)
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestDispatcherRemoveWhileDispatching(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var second *gateway.Subscription
+	var calls int
+
+	d.Add("Event", func(name string, v any) error {
+		second.Remove()
+		return nil
+	})
+	second = d.Add("Event", func(name string, v any) error {
+		calls++
+		return nil
+	})
+
+	d.Dispatch("Event", nil, nil)
+
+	if calls != 0 {
+		t.Errorf("expected a handler removed by an earlier handler not to be called, got %d calls", calls)
+	}
+}
+
+func TestDispatcherOnce(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var calls int
+	d.Once("Event", func(name string, v any) error {
+		calls++
+		// Dispatching from the handler must not call it again
+		d.Dispatch("Event", nil, nil)
+		return nil
+	})
+
+	d.Dispatch("Event", nil, nil)
+	d.Dispatch("Event", nil, nil)
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestDispatcherAny(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var names []string
+	d.Add(gateway.Any, func(name string, v any) error {
+		names = append(names, name)
+		return nil
+	})
+
+	d.Dispatch("First", nil, nil)
+	d.Dispatch("Second", nil, nil)
+
+	if len(names) != 2 || names[0] != "First" || names[1] != "Second" {
+		t.Errorf("expected First and Second, got %v", names)
+	}
+}
+
+func TestDispatcherRecoversPanics(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var reported []*gateway.HandlerError
+	d.OnError = func(err *gateway.HandlerError) {
+		reported = append(reported, err)
+	}
+
+	failed := errors.New("failed")
+	var calls int
+
+	d.Add("Event", func(name string, v any) error {
+		panic("boom")
+	})
+	d.Add("Event", func(name string, v any) error {
+		return failed
+	})
+	d.Add("Event", func(name string, v any) error {
+		calls++
+		return nil
+	})
+
+	d.Dispatch("Event", "value", json.RawMessage(`{"id":"1"}`))
+
+	if calls != 1 {
+		t.Error("expected the handlers after a panic to be called")
+	}
+
+	if len(reported) != 2 {
+		t.Errorf("expected 2 errors, got %d", len(reported))
+		t.FailNow()
+	}
+
+	p := reported[0]
+	if p.Event != "Event" || p.Value != "value" || string(p.Payload) != `{"id":"1"}` || p.Recovered != "boom" || len(p.Stack) == 0 {
+		t.Errorf("unexpected panic report: %+v", p)
+	}
+
+	if !errors.Is(reported[1], failed) || reported[1].Recovered != nil {
+		t.Errorf("expected the returned error, got %+v", reported[1])
+	}
+}
+
+func TestDispatcherMiddleware(t *testing.T) {
+	d := gateway.NewDispatcher()
+
+	var order []string
+	d.Use(func(next gateway.Next) gateway.Next {
+		return func(name string, v any) {
+			order = append(order, "outer")
+			next(name, v)
+		}
+	}, func(next gateway.Next) gateway.Next {
+		return func(name string, v any) {
+			order = append(order, "inner")
+
+			// Drop ignored events and enrich the others
+			if v == "ignored" {
+				return
+			}
+
+			next(name, v.(string)+" enriched")
+		}
+	})
+
+	var received []any
+	d.Add("Event", func(name string, v any) error {
+		received = append(received, v)
+		return nil
+	})
+
+	d.Dispatch("Event", "ignored", nil)
+	d.Dispatch("Event", "value", nil)
+
+	if len(order) != 4 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("expected the first middleware to run first, got %v", order)
+	}
+
+	if len(received) != 1 || received[0] != "value enriched" {
+		t.Errorf("expected only the enriched value, got %v", received)
+	}
+}
@@ -0,0 +1,277 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultWorkers   = 8
+	DefaultQueueSize = 128
+)
+
+// Policy decides what happens to a task when the queue of its worker is full
+type Policy int
+
+const (
+	// Block waits until the worker has room. This slows down reading from the gateway
+	Block Policy = iota
+
+	// Drop discards the task and counts it in PoolStats.Dropped
+	Drop
+)
+
+type PoolConfig struct {
+	// Workers is the number of goroutines running tasks, DefaultWorkers if zero
+	Workers int
+
+	// QueueSize is the number of tasks buffered per worker, DefaultQueueSize if zero
+	QueueSize int
+
+	Policy Policy
+}
+
+// PoolStats is a snapshot of the pool's queues and counters
+type PoolStats struct {
+	Workers   int
+	QueueSize int
+
+	// Tasks waiting in the queues right now
+	Queued int
+
+	// Tasks run since the pool was created
+	Dispatched uint64
+
+	// Tasks discarded because of a full queue with the Drop policy,
+	// or because the pool stopped while they waited for room with the Block policy
+	Dropped uint64
+
+	// Tasks that had to wait for a full queue, only with the Block policy
+	Blocked uint64
+
+	// The total time spent waiting for full queues
+	BlockedTime time.Duration
+}
+
+// Pool runs tasks on a fixed number of workers. Tasks with the same key always run on the
+// same worker, in the order they were submitted.
+type Pool struct {
+	workers   int
+	queueSize int
+	policy    Policy
+
+	mu     sync.RWMutex
+	queues []chan func()
+	wg     sync.WaitGroup
+
+	// stop is closed by Stop, so that Submit stops waiting for room in a full queue
+	stop     chan struct{}
+	stopOnce *sync.Once
+
+	dispatched  atomic.Uint64
+	dropped     atomic.Uint64
+	blocked     atomic.Uint64
+	blockedTime atomic.Int64
+}
+
+func NewPool(cfg PoolConfig) *Pool {
+	p := &Pool{
+		workers:   cfg.Workers,
+		queueSize: cfg.QueueSize,
+		policy:    cfg.Policy,
+	}
+
+	if p.workers <= 0 {
+		p.workers = DefaultWorkers
+	}
+
+	if p.queueSize <= 0 {
+		p.queueSize = DefaultQueueSize
+	}
+
+	return p
+}
+
+// Start starts the workers. Starting a running pool is a no-op
+func (p *Pool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.queues != nil {
+		return
+	}
+
+	p.queues = make([]chan func(), p.workers)
+	p.stop = make(chan struct{})
+	p.stopOnce = &sync.Once{}
+	for i := range p.queues {
+		q := make(chan func(), p.queueSize)
+		p.queues[i] = q
+
+		p.wg.Add(1)
+		go p.work(q)
+	}
+}
+
+func (p *Pool) work(q chan func()) {
+	defer p.wg.Done()
+
+	for task := range q {
+		task()
+		p.dispatched.Add(1)
+	}
+}
+
+// Submit queues task on the worker for key. If the pool isn't running, task runs right away.
+// It returns false if the task was dropped, because the queue was full or the pool stopped while waiting for room.
+func (p *Pool) Submit(key string, task func()) bool {
+	p.mu.RLock()
+	if p.queues == nil {
+		p.mu.RUnlock()
+
+		task()
+		p.dispatched.Add(1)
+		return true
+	}
+	defer p.mu.RUnlock()
+
+	q := p.queues[p.worker(key)]
+
+	select {
+	case q <- task:
+		return true
+	default:
+	}
+
+	if p.policy == Drop {
+		p.dropped.Add(1)
+		return false
+	}
+
+	start := time.Now()
+	defer func() {
+		p.blocked.Add(1)
+		p.blockedTime.Add(int64(time.Since(start)))
+	}()
+
+	select {
+	case q <- task:
+		return true
+	case <-p.stop:
+		p.dropped.Add(1)
+		return false
+	}
+}
+
+func (p *Pool) worker(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(p.workers))
+}
+
+// Stop stops accepting tasks and waits until the queued ones are done, or ctx is done.
+// Tasks submitted afterwards run right away, until the pool is started again.
+func (p *Pool) Stop(ctx context.Context) error {
+	// Submit holds the read lock while it waits for room, let it give up first
+	p.mu.RLock()
+	if p.stopOnce != nil {
+		p.stopOnce.Do(func() { close(p.stop) })
+	}
+	p.mu.RUnlock()
+
+	p.mu.Lock()
+	queues := p.queues
+	p.queues = nil
+	p.mu.Unlock()
+
+	for _, q := range queues {
+		close(q)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) Stats() PoolStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var queued int
+	for _, q := range p.queues {
+		queued += len(q)
+	}
+
+	return PoolStats{
+		Workers:     p.workers,
+		QueueSize:   p.queueSize,
+		Queued:      queued,
+		Dispatched:  p.dispatched.Load(),
+		Dropped:     p.dropped.Load(),
+		Blocked:     p.blocked.Load(),
+		BlockedTime: time.Duration(p.blockedTime.Load()),
+	}
+}
+
+// OrderingKey returns the ID events have to be ordered by: the channel ID of the event data,
+// e.g. d.message.channelId, or the server ID for events that don't belong to a channel.
+func OrderingKey(data json.RawMessage) string {
+	id := ChannelID(data)
+	if id != "" {
+		return id
+	}
+
+	var ids struct {
+		ServerID string `json:"serverId"`
+	}
+
+	json.Unmarshal(data, &ids)
+	return ids.ServerID
+}
+
+// ChannelID returns the channel ID of the event data, either d.channelId or that of a nested object,
+// e.g. d.message.channelId. It is empty for events that don't belong to a channel
+func ChannelID(data json.RawMessage) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return ""
+	}
+
+	var id string
+	if json.Unmarshal(fields["channelId"], &id) == nil && id != "" {
+		return id
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var nested struct {
+			ChannelID string `json:"channelId"`
+		}
+
+		if json.Unmarshal(fields[k], &nested) == nil && nested.ChannelID != "" {
+			return nested.ChannelID
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,114 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo/internal/gateway"
+)
+
+func TestPoolKeepsOrderPerKey(t *testing.T) {
+	p := gateway.NewPool(gateway.PoolConfig{Workers: 4, QueueSize: 4})
+	p.Start()
+
+	var mu sync.Mutex
+	received := make(map[string][]int)
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("channel", i%5)
+		i := i
+
+		p.Submit(key, func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			received[key] = append(received[key], i)
+		})
+	}
+
+	err := p.Stop(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+
+	for key, ids := range received {
+		for j := 1; j < len(ids); j++ {
+			if ids[j] < ids[j-1] {
+				t.Errorf("%s: events out of order: %v", key, ids)
+				break
+			}
+		}
+	}
+
+	if stats := p.Stats(); stats.Dispatched != 100 {
+		t.Errorf("expected 100 dispatched tasks, got %d", stats.Dispatched)
+	}
+}
+
+func TestPoolDropsWhenFull(t *testing.T) {
+	p := gateway.NewPool(gateway.PoolConfig{Workers: 1, QueueSize: 1, Policy: gateway.Drop})
+	p.Start()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	p.Submit("", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	if !p.Submit("", func() {}) {
+		t.Error("expected the task to fit in the queue")
+	}
+
+	if p.Submit("", func() {}) {
+		t.Error("expected the task to be dropped")
+	}
+
+	if stats := p.Stats(); stats.Dropped != 1 || stats.Queued != 1 {
+		t.Errorf("expected 1 dropped and 1 queued task, got %+v", stats)
+	}
+
+	close(release)
+	p.Stop(context.Background())
+}
+
+func TestPoolStopDeadline(t *testing.T) {
+	p := gateway.NewPool(gateway.PoolConfig{Workers: 1})
+	p.Start()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	p.Submit("", func() {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := p.Stop(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestOrderingKey(t *testing.T) {
+	tests := map[string]string{
+		`{"serverId":"s","message":{"id":"1","channelId":"c"}}`: "c",
+		`{"serverId":"s","channelId":"c"}`:                      "c",
+		`{"serverId":"s","member":{"user":{"id":"u"}}}`:         "s",
+		`[]`: "",
+	}
+
+	for data, want := range tests {
+		if key := gateway.OrderingKey(json.RawMessage(data)); key != want {
+			t.Errorf("%s: expected %q, got %q", data, want, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnknownEvent is returned when decoding an event type that isn't registered
+var ErrUnknownEvent = errors.New("gateway: unknown event type")
+
+// Registry maps gateway event names to constructors for the values they are decoded into,
+// and Go types back to event names. Every type belongs to exactly one event.
+// Every event is decoded into a freshly allocated value, so handlers can keep it around.
+type Registry struct {
+	constructors map[string]func() any
+	names        map[reflect.Type]string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		constructors: make(map[string]func() any),
+		names:        make(map[reflect.Type]string),
+	}
+}
+
+// Register decodes events named name into a new *T. It panics if T or name is already registered
+func Register[T any](r *Registry, name string) {
+	t := typeOf[T]()
+
+	if other, ok := r.names[t]; ok {
+		panic(fmt.Sprintf("gateway: %s is already registered for %s", t, other))
+	}
+
+	if _, ok := r.constructors[name]; ok {
+		panic(fmt.Sprintf("gateway: %s is already registered", name))
+	}
+
+	r.names[t] = name
+	r.constructors[name] = func() any {
+		return new(T)
+	}
+}
+
+// NameOf returns the event name T is registered for
+func NameOf[T any](r *Registry) (string, bool) {
+	return r.Name(typeOf[T]())
+}
+
+// Name returns the event name t is registered for. Pointer types are resolved to their element type
+func (r *Registry) Name(t reflect.Type) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	name, ok := r.names[t]
+	return name, ok
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// New returns a new zero value for the event, or false if the event isn't registered
+func (r *Registry) New(name string) (any, bool) {
+	newValue, ok := r.constructors[name]
+	if !ok {
+		return nil, false
+	}
+
+	return newValue(), true
+}
+
+// Decode unmarshals data into a new value for the event. If unmarshaling fails part way
+// the value is returned along with the error, as most fields are usually still usable.
+func (r *Registry) Decode(name string, data json.RawMessage) (any, error) {
+	v, ok := r.New(name)
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+
+	return v, json.Unmarshal(data, v)
+}
@@ -0,0 +1,77 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/itschip/guildedgo/internal/gateway"
+)
+
+type testMessage struct {
+	ID        string   `json:"id"`
+	IsPrivate bool     `json:"isPrivate,omitempty"`
+	Mentions  []string `json:"mentions,omitempty"`
+}
+
+func TestRegistryDecodesIntoFreshValues(t *testing.T) {
+	r := gateway.NewRegistry()
+	gateway.Register[testMessage](r, "TestMessage")
+
+	first, err := r.Decode("TestMessage", json.RawMessage(`{"id":"1","isPrivate":true,"mentions":["a"]}`))
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	second, err := r.Decode("TestMessage", json.RawMessage(`{"id":"2"}`))
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	a, b := first.(*testMessage), second.(*testMessage)
+	if a == b {
+		t.Error("expected every event to be decoded into a new value")
+	}
+
+	if b.IsPrivate || b.Mentions != nil {
+		t.Errorf("fields of the first event leaked into the second: %+v", b)
+	}
+
+	if a.ID != "1" || !a.IsPrivate || len(a.Mentions) != 1 {
+		t.Errorf("the first event was changed by the second: %+v", a)
+	}
+}
+
+func TestRegistryUnknownEvent(t *testing.T) {
+	r := gateway.NewRegistry()
+
+	_, err := r.Decode("Unknown", json.RawMessage(`{}`))
+	if err != gateway.ErrUnknownEvent {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	r := gateway.NewRegistry()
+	gateway.Register[testMessage](r, "TestMessage")
+
+	name, ok := gateway.NameOf[testMessage](r)
+	if !ok || name != "TestMessage" {
+		t.Errorf("expected TestMessage, got %q", name)
+	}
+
+	name, ok = r.Name(reflect.TypeOf(&testMessage{}))
+	if !ok || name != "TestMessage" {
+		t.Errorf("expected pointers to resolve to TestMessage, got %q", name)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a type twice to panic")
+		}
+	}()
+
+	gateway.Register[testMessage](r, "OtherMessage")
+}
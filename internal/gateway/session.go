@@ -0,0 +1,645 @@
+// Package gateway contains the websocket connection to Guilded shared by the guildedgo client and pkg/client
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultMinBackoff is the delay before the first reconnect attempt
+	DefaultMinBackoff = time.Second
+
+	// DefaultMaxBackoff is the upper bound for the delay between reconnect attempts
+	DefaultMaxBackoff = 2 * time.Minute
+
+	// closeTimeout is how long Close waits for Guilded to acknowledge the close frame, see Shutdown
+	closeTimeout = time.Second
+
+	// writeTimeout bounds writing heartbeats and close frames
+	writeTimeout = 10 * time.Second
+
+	// LastMessageIDHeader tells Guilded which message the client saw last, so that missed events are replayed
+	LastMessageIDHeader = "guilded-last-message-id"
+
+	// DefaultMissedHeartbeats is how many heartbeats may go unanswered before the connection is considered dead
+	DefaultMissedHeartbeats = 2
+)
+
+var (
+	// ErrClosed is returned when the session was closed
+	ErrClosed = errors.New("gateway: session closed")
+
+	// ErrResyncRequired is returned when Guilded can't replay the events missed since the last message ID
+	ErrResyncRequired = errors.New("gateway: events can't be replayed, resync required")
+
+	// ErrUnauthorized is returned when Guilded rejects the token. The session doesn't reconnect after it
+	ErrUnauthorized = errors.New("gateway: unauthorized")
+
+	// ErrMissedHeartbeats is passed to OnDisconnect when Guilded stopped answering heartbeats
+	ErrMissedHeartbeats = errors.New("gateway: missed heartbeats")
+
+	newline = []byte{'\n'}
+	space   = []byte{' '}
+)
+
+// Op codes sent by Guilded
+const (
+	OpEvent         = 0
+	OpWelcome       = 1
+	OpResume        = 2
+	OpInvalidCursor = 8
+	OpInternalError = 9
+)
+
+type Config struct {
+	URL    string
+	Dialer *websocket.Dialer
+
+	// LastMessageID resumes a previous session, e.g. one from before a restart
+	LastMessageID string
+
+	// Header returns the headers to connect with, it is called for every connection attempt
+	Header func() http.Header
+
+	// MinBackoff and MaxBackoff bound the delay between reconnect attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MissedHeartbeats is how many heartbeats may go unanswered before the session reconnects,
+	// DefaultMissedHeartbeats if zero
+	MissedHeartbeats int
+
+	// Logger receives connection problems, slog.Default() if nil
+	Logger *slog.Logger
+
+	// OnMessage is called with every message after the welcome message, on the read goroutine
+	OnMessage func(msg []byte)
+
+	// OnConnect is called whenever a connection is established, including reconnects
+	OnConnect func()
+
+	// OnDisconnect is called when an established connection is lost
+	OnDisconnect func(err error)
+
+	// OnReconnect is called before every reconnect attempt
+	OnReconnect func(attempt int, delay time.Duration)
+
+	// OnResume is called once Guilded replayed the events missed while disconnected
+	OnResume func(lastMessageID string)
+
+	// OnResync is called when the missed events can't be replayed. The session reconnects
+	// without a last message ID, so state that depends on events should be fetched again
+	OnResync func(op int)
+}
+
+// Session is a supervised gateway connection. Dropped connections, missed heartbeats
+// and close frames sent by Guilded are followed by a reconnect with exponential backoff.
+type Session struct {
+	cfg Config
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	closing chan struct{}
+	stopped chan struct{}
+	err     error
+
+	// idMu guards lastMessageID separately, connect reads it while mu is held by Open
+	idMu          sync.Mutex
+	lastMessageID string
+
+	statsMu     sync.Mutex
+	latency     time.Duration
+	connectedAt time.Time
+	reconnects  int
+	events      map[string]uint64
+	lastEvent   time.Time
+}
+
+// SessionStats is a snapshot of the connection health, see Session.Stats
+type SessionStats struct {
+	// The round trip time of the last answered heartbeat, zero before the first one
+	Latency time.Duration
+
+	// How long the current connection has been up, zero while disconnected
+	Uptime time.Duration
+
+	// The number of times the session reconnected since it was opened
+	Reconnects int
+
+	// The number of events received per event name
+	Events map[string]uint64
+
+	// When the last event was received, zero if none was
+	LastEvent time.Time
+}
+
+type welcome struct {
+	HeartbeatIntervalMs int    `json:"heartbeatIntervalMs"`
+	LastMessageID       string `json:"lastMessageId"`
+}
+
+type envelope struct {
+	Op   int             `json:"op"`
+	T    string          `json:"t"`
+	S    string          `json:"s"`
+	Data json.RawMessage `json:"d"`
+}
+
+func New(cfg Config) *Session {
+	if cfg.Dialer == nil {
+		cfg.Dialer = websocket.DefaultDialer
+	}
+
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if cfg.MissedHeartbeats <= 0 {
+		cfg.MissedHeartbeats = DefaultMissedHeartbeats
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	return &Session{
+		cfg:           cfg,
+		lastMessageID: cfg.LastMessageID,
+	}
+}
+
+// LastMessageID returns the ID of the last event received, which is sent when reconnecting
+func (s *Session) LastMessageID() string {
+	s.idMu.Lock()
+	defer s.idMu.Unlock()
+
+	return s.lastMessageID
+}
+
+func (s *Session) setLastMessageID(id string) {
+	s.idMu.Lock()
+	s.lastMessageID = id
+	s.idMu.Unlock()
+}
+
+// Open connects to the gateway and keeps the connection alive until Close is called.
+// Only the first connection attempt returns an error, later failures are retried.
+func (s *Session) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing != nil {
+		return nil
+	}
+
+	conn, w, err := s.connect()
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.closing = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.err = nil
+
+	s.statsMu.Lock()
+	s.latency = 0
+	s.reconnects = 0
+	s.events = make(map[string]uint64)
+	s.lastEvent = time.Time{}
+	s.statsMu.Unlock()
+
+	go s.run(conn, w, s.closing, s.stopped)
+
+	return nil
+}
+
+// Done is closed when the session stops, either because it was closed or because it gave up reconnecting.
+// It returns nil if the session was never opened.
+func (s *Session) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stopped
+}
+
+// Err returns why the session stopped on its own, e.g. ErrUnauthorized. It is nil while the session runs
+// and after it was closed.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Close sends a close frame and waits briefly for Guilded to acknowledge it before closing the connection
+func (s *Session) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	return s.Shutdown(ctx)
+}
+
+// Shutdown sends a close frame and waits until Guilded acknowledges it or ctx is done,
+// then closes the connection. It returns ctx's error if the read loop didn't stop in time
+func (s *Session) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closing == nil {
+		s.mu.Unlock()
+		return nil
+	}
+
+	close(s.closing)
+	s.closing = nil
+
+	conn := s.conn
+	stopped := s.stopped
+	s.mu.Unlock()
+
+	var err error
+	if conn != nil {
+		err = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(writeTimeout))
+	}
+
+	// The read loop exits once Guilded echoes the close frame
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	conn = s.conn
+	s.conn = nil
+	s.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+
+	// The read loop may be stuck handing a message to a blocked handler, don't wait for it past ctx
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	return err
+}
+
+func (s *Session) connect() (*websocket.Conn, *welcome, error) {
+	header := http.Header{}
+	if s.cfg.Header != nil {
+		if h := s.cfg.Header(); h != nil {
+			header = h
+		}
+	}
+
+	if id := s.LastMessageID(); id != "" {
+		header.Set(LastMessageIDHeader, id)
+	}
+
+	conn, resp, err := s.cfg.Dialer.Dial(s.cfg.URL, header)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnauthorized, resp.Status)
+		}
+
+		return nil, nil, fmt.Errorf("failed to connect to websocket: %w", err)
+	}
+
+	// Echoing the close frame is handled by Close
+	conn.SetCloseHandler(func(code int, text string) error {
+		return nil
+	})
+
+	// Get the welcome message from Guilded
+	_, m, err := conn.ReadMessage()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to read welcome message: %w", err)
+	}
+
+	var e envelope
+	err = json.Unmarshal(trim(m), &e)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to decode welcome message: %w", err)
+	}
+
+	if e.Op != OpWelcome {
+		conn.Close()
+		return nil, nil, fmt.Errorf("expected op code 1, got %d", e.Op)
+	}
+
+	var w welcome
+	err = json.Unmarshal(e.Data, &w)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to decode welcome message: %w", err)
+	}
+
+	// Without a previous session, resume from the last message Guilded sent to the bot
+	if s.LastMessageID() == "" {
+		s.setLastMessageID(w.LastMessageID)
+	}
+
+	s.statsMu.Lock()
+	s.connectedAt = time.Now()
+	s.statsMu.Unlock()
+
+	if s.cfg.OnConnect != nil {
+		s.cfg.OnConnect()
+	}
+
+	return conn, &w, nil
+}
+
+// run serves the connection and reconnects whenever it is lost, until closing is closed
+func (s *Session) run(conn *websocket.Conn, w *welcome, closing, stopped chan struct{}) {
+	defer close(stopped)
+
+	for {
+		err := s.serve(conn, w, closing)
+
+		select {
+		case <-closing:
+			return
+		default:
+		}
+
+		conn.Close()
+
+		s.statsMu.Lock()
+		s.connectedAt = time.Time{}
+		s.statsMu.Unlock()
+
+		if s.cfg.OnDisconnect != nil {
+			s.cfg.OnDisconnect(err)
+		}
+
+		conn, w, err = s.reconnect(closing)
+		if conn == nil {
+			s.mu.Lock()
+			if s.closing == closing {
+				s.closing = nil
+				s.conn = nil
+				s.err = err
+			}
+			s.mu.Unlock()
+			return
+		}
+
+		s.mu.Lock()
+		select {
+		case <-closing:
+			s.mu.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		s.conn = conn
+		s.mu.Unlock()
+
+		s.statsMu.Lock()
+		s.reconnects++
+		s.statsMu.Unlock()
+	}
+}
+
+// reconnect dials until it succeeds. It returns ErrClosed if the session is closed, or the error if the token is rejected
+func (s *Session) reconnect(closing chan struct{}) (*websocket.Conn, *welcome, error) {
+	for attempt := 1; ; attempt++ {
+		delay := s.backoff(attempt)
+
+		if s.cfg.OnReconnect != nil {
+			s.cfg.OnReconnect(attempt, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-closing:
+			timer.Stop()
+			return nil, nil, ErrClosed
+		case <-timer.C:
+		}
+
+		conn, w, err := s.connect()
+		if err == nil {
+			return conn, w, nil
+		}
+
+		s.cfg.Logger.Warn("Failed to reconnect", "attempt", attempt, "error", err)
+
+		if errors.Is(err, ErrUnauthorized) {
+			return nil, nil, err
+		}
+	}
+}
+
+// backoff doubles the delay with every attempt and randomizes it by up to a half,
+// so that many bots don't reconnect in lockstep after an outage
+func (s *Session) backoff(attempt int) time.Duration {
+	d := s.cfg.MinBackoff
+	for i := 1; i < attempt && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// serve reads from the connection until it fails or the session is closed
+func (s *Session) serve(conn *websocket.Conn, w *welcome, closing chan struct{}) error {
+	interval := time.Duration(w.HeartbeatIntervalMs) * time.Millisecond
+
+	// Any message or pong proves the connection is alive
+	alive := func() {
+		if interval > 0 {
+			conn.SetReadDeadline(time.Now().Add(interval * time.Duration(s.cfg.MissedHeartbeats+1)))
+		}
+	}
+
+	var unanswered atomic.Int32
+
+	alive()
+	conn.SetPongHandler(func(data string) error {
+		unanswered.Store(0)
+		alive()
+
+		// Pongs echo the time the ping was sent
+		sent, err := strconv.ParseInt(data, 10, 64)
+		if err == nil {
+			latency := time.Since(time.Unix(0, sent))
+
+			s.statsMu.Lock()
+			s.latency = latency
+			s.statsMu.Unlock()
+
+			s.cfg.Logger.Debug("Heartbeat acknowledged", "latency", latency)
+		}
+
+		return nil
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+
+	dead := make(chan struct{})
+	if interval > 0 {
+		go s.heartbeat(conn, interval, &unanswered, done, dead)
+	}
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-closing:
+				return ErrClosed
+			case <-dead:
+				return fmt.Errorf("%w: no answer to %d heartbeats", ErrMissedHeartbeats, s.cfg.MissedHeartbeats)
+			default:
+			}
+
+			// The read deadline passed, neither messages nor pongs arrived in time
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return fmt.Errorf("%w: %w", ErrMissedHeartbeats, err)
+			}
+
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				return fmt.Errorf("connection closed by guilded: %w", err)
+			}
+
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		alive()
+
+		msg = trim(msg)
+
+		var e envelope
+		err = json.Unmarshal(msg, &e)
+		if err != nil {
+			s.cfg.Logger.Error("Failed to decode gateway message", "error", err)
+			continue
+		}
+
+		switch e.Op {
+		case OpEvent:
+			if e.S != "" {
+				s.setLastMessageID(e.S)
+			}
+
+			s.statsMu.Lock()
+			s.events[e.T]++
+			s.lastEvent = time.Now()
+			s.statsMu.Unlock()
+		case OpResume:
+			var d struct {
+				LastMessageID string `json:"lastMessageId"`
+			}
+			if json.Unmarshal(e.Data, &d) == nil && d.LastMessageID != "" {
+				s.setLastMessageID(d.LastMessageID)
+			}
+
+			if s.cfg.OnResume != nil {
+				s.cfg.OnResume(s.LastMessageID())
+			}
+		case OpInvalidCursor, OpInternalError:
+			s.setLastMessageID("")
+
+			if s.cfg.OnResync != nil {
+				s.cfg.OnResync(e.Op)
+			}
+		}
+
+		if s.cfg.OnMessage != nil {
+			s.cfg.OnMessage(msg)
+		}
+
+		if e.Op == OpInvalidCursor || e.Op == OpInternalError {
+			return fmt.Errorf("%w (op %d)", ErrResyncRequired, e.Op)
+		}
+	}
+}
+
+// heartbeat pings Guilded every interval and closes the connection once MissedHeartbeats pings went unanswered
+func (s *Session) heartbeat(conn *websocket.Conn, interval time.Duration, unanswered *atomic.Int32, done, dead chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if int(unanswered.Load()) >= s.cfg.MissedHeartbeats {
+				// Closing the connection makes serve return and the session reconnect
+				close(dead)
+				conn.Close()
+				return
+			}
+
+			unanswered.Add(1)
+
+			err := conn.WriteControl(websocket.PingMessage, []byte(strconv.FormatInt(time.Now().UnixNano(), 10)), time.Now().Add(writeTimeout))
+			if err != nil {
+				// Unanswered heartbeats take care of the dead connection
+				s.cfg.Logger.Warn("Failed to send heartbeat", "error", err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the connection health
+func (s *Session) Stats() SessionStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats := SessionStats{
+		Latency:    s.latency,
+		Reconnects: s.reconnects,
+		Events:     make(map[string]uint64, len(s.events)),
+		LastEvent:  s.lastEvent,
+	}
+
+	if !s.connectedAt.IsZero() {
+		stats.Uptime = time.Since(s.connectedAt)
+	}
+
+	for name, n := range s.events {
+		stats.Events[name] = n
+	}
+
+	return stats
+}
+
+func trim(msg []byte) []byte {
+	return bytes.TrimSpace(bytes.Replace(msg, newline, space, -1))
+}
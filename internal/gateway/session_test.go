@@ -0,0 +1,286 @@
+package gateway_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/itschip/guildedgo/internal/gateway"
+)
+
+// fakeGuilded accepts websocket connections, sends the welcome message and hands the connection to serve
+func fakeGuilded(t *testing.T, serve func(conn *websocket.Conn, r *http.Request)) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":1,"d":{"heartbeatIntervalMs":22500}}`))
+
+		serve(conn, r)
+	}))
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestSessionReconnects(t *testing.T) {
+	var connections int32
+
+	srv := fakeGuilded(t, func(conn *websocket.Conn, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) == 1 {
+			// Drop the first connection
+			return
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":0,"t":"ChatMessageCreated","s":"1","d":{}}`))
+		conn.ReadMessage()
+	})
+	defer srv.Close()
+
+	var connects, disconnects, reconnects int32
+	messages := make(chan []byte, 1)
+
+	s := gateway.New(gateway.Config{
+		URL:        wsURL(srv),
+		MinBackoff: time.Millisecond,
+		OnMessage: func(msg []byte) {
+			messages <- msg
+		},
+		OnConnect: func() {
+			atomic.AddInt32(&connects, 1)
+		},
+		OnDisconnect: func(err error) {
+			atomic.AddInt32(&disconnects, 1)
+		},
+		OnReconnect: func(attempt int, delay time.Duration) {
+			atomic.AddInt32(&reconnects, 1)
+		},
+	})
+
+	err := s.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer s.Close()
+
+	select {
+	case <-messages:
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for a message after reconnecting")
+		t.FailNow()
+	}
+
+	if connects != 2 || disconnects != 1 || reconnects != 1 {
+		t.Errorf("expected 2 connects, 1 disconnect and 1 reconnect, got %d, %d and %d", connects, disconnects, reconnects)
+	}
+}
+
+func TestSessionOpenFailsOnUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	s := gateway.New(gateway.Config{URL: wsURL(srv)})
+
+	err := s.Open()
+	if err == nil {
+		s.Close()
+		t.Error("expected an error")
+		t.FailNow()
+	}
+
+	if !errors.Is(err, gateway.ErrUnauthorized) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestSessionStopsOnUnauthorized(t *testing.T) {
+	var connections int32
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) > 1 {
+			// The token was revoked while the first connection was open
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":1,"d":{"heartbeatIntervalMs":22500}}`))
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	s := gateway.New(gateway.Config{URL: wsURL(srv), MinBackoff: time.Millisecond})
+
+	err := s.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer s.Close()
+
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the session to stop")
+		t.FailNow()
+	}
+
+	if !errors.Is(s.Err(), gateway.ErrUnauthorized) {
+		t.Errorf("expected an unauthorized error, got %v", s.Err())
+	}
+}
+
+func TestSessionResumes(t *testing.T) {
+	var connections int32
+	headers := make(chan string, 3)
+
+	srv := fakeGuilded(t, func(conn *websocket.Conn, r *http.Request) {
+		headers <- r.Header.Get(gateway.LastMessageIDHeader)
+
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"op":0,"t":"ChatMessageCreated","s":"abc","d":{}}`))
+		case 2:
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"op":2,"d":{"lastMessageId":"def"}}`))
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"op":8,"d":{}}`))
+		default:
+			conn.ReadMessage()
+		}
+	})
+	defer srv.Close()
+
+	resumed := make(chan string, 1)
+	resync := make(chan int, 1)
+
+	s := gateway.New(gateway.Config{
+		URL:        wsURL(srv),
+		MinBackoff: time.Millisecond,
+		OnResume: func(lastMessageID string) {
+			resumed <- lastMessageID
+		},
+		OnResync: func(op int) {
+			resync <- op
+		},
+	})
+
+	err := s.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer s.Close()
+
+	want := []string{"", "abc", ""}
+	for i, w := range want {
+		select {
+		case h := <-headers:
+			if h != w {
+				t.Errorf("connection %d: expected last message ID %q, got %q", i+1, w, h)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for connection", i+1)
+			t.FailNow()
+		}
+	}
+
+	if id := <-resumed; id != "def" {
+		t.Errorf("expected resume from def, got %q", id)
+	}
+
+	if op := <-resync; op != gateway.OpInvalidCursor {
+		t.Errorf("expected resync after op 8, got op %d", op)
+	}
+}
+
+func TestSessionReconnectsOnMissedHeartbeats(t *testing.T) {
+	var connections int32
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":1,"d":{"heartbeatIntervalMs":20}}`))
+
+		if atomic.AddInt32(&connections, 1) == 1 {
+			// Leave the pings of the first connection unanswered
+			conn.SetPingHandler(func(string) error { return nil })
+		} else {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"op":0,"t":"ChatMessageCreated","s":"1","d":{}}`))
+		}
+
+		for {
+			_, _, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	disconnected := make(chan error, 1)
+	s := gateway.New(gateway.Config{
+		URL:        wsURL(srv),
+		MinBackoff: time.Millisecond,
+		OnDisconnect: func(err error) {
+			disconnected <- err
+		},
+	})
+
+	err := s.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer s.Close()
+
+	select {
+	case err := <-disconnected:
+		if !errors.Is(err, gateway.ErrMissedHeartbeats) {
+			t.Errorf("expected a missed heartbeats error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the dead connection to be detected")
+		t.FailNow()
+	}
+
+	// Wait for the second connection to answer a heartbeat
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Stats().Latency == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stats := s.Stats()
+	if stats.Latency == 0 || stats.Uptime == 0 || stats.Reconnects != 1 {
+		t.Errorf("expected a latency, an uptime and 1 reconnect, got %+v", stats)
+	}
+
+	if stats.Events["ChatMessageCreated"] != 1 || stats.LastEvent.IsZero() {
+		t.Errorf("expected 1 ChatMessageCreated event, got %+v", stats)
+	}
+}
@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when Guilded responds with a non 2xx status code
+type APIError struct {
+	// The HTTP status code of the response
+	StatusCode int
+
+	// The error code sent by Guilded, e.g. "NotFound" or "ForbiddenError"
+	Code string
+
+	// The error message sent by Guilded
+	Message string
+
+	// Additional information about the error, if Guilded sent any
+	Meta map[string]any
+
+	// The method and URL of the request that failed
+	Method string
+	URL    string
+
+	// The headers of the response
+	Header http.Header
+
+	// The ID Guilded assigned to the request, if it was sent back in the response headers
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+
+	if e.Code == "" {
+		return fmt.Sprintf("api error (%d) %s %s: %s", e.StatusCode, e.Method, e.URL, message)
+	}
+
+	return fmt.Sprintf("api error (%d, %s) %s %s: %s", e.StatusCode, e.Code, e.Method, e.URL, message)
+}
+
+var requestIDHeaders = []string{"Guilded-Request-Id", "X-Request-Id"}
+
+// CheckResponse returns an *APIError if the response status is not 2xx
+func CheckResponse(r *Request, resp *Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     r.Method,
+		URL:        r.URL,
+		Header:     resp.Header,
+	}
+
+	// The body is not always JSON, e.g. when a proxy in between fails
+	var body struct {
+		Code    string         `json:"code"`
+		Message string         `json:"message"`
+		Meta    map[string]any `json:"meta"`
+	}
+	if json.Unmarshal(resp.Body, &body) == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Message
+		apiErr.Meta = body.Meta
+	}
+
+	for _, key := range requestIDHeaders {
+		if id := resp.Header.Get(key); id != "" {
+			apiErr.RequestID = id
+			break
+		}
+	}
+
+	return apiErr
+}
+
+// HasStatus reports whether err is an *APIError with one of the given status codes
+func HasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsServerError reports whether err is an *APIError with a 5xx status code
+func IsServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
@@ -0,0 +1,30 @@
+package rest
+
+import "net/http"
+
+// Handler sends a single HTTP request. The innermost handler is the HTTP client itself
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or change requests and responses.
+// It runs for every attempt, so retried requests pass through it again.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the chain. The first middleware added is the outermost one
+func (c *Client) Use(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.middleware = append(c.middleware, mw...)
+}
+
+func (c *Client) handler() Handler {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	h := Handler(c.HTTPClient.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+
+	return h
+}
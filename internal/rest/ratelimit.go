@@ -0,0 +1,191 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxRateLimitRetries is how many times a request is replayed after a 429 before giving up
+	DefaultMaxRateLimitRetries = 5
+
+	// defaultRetryAfter is used when Guilded responds with 429 but omits (or sends an invalid) Retry-After header
+	defaultRetryAfter = time.Second
+)
+
+// Bucket is a snapshot of the rate limit state for a single route
+type Bucket struct {
+	// The route the bucket applies to, e.g. "DELETE /servers/abc/members/:id"
+	Route string
+
+	// When requests on this route may be sent again. Zero if the route was never limited
+	ResetAt time.Time
+
+	// The number of 429 responses received on this route
+	Hits int
+
+	// The number of requests currently waiting for the bucket to reset
+	Queued int
+}
+
+// Limited reports whether the bucket is still waiting for its reset
+func (b Bucket) Limited() bool {
+	return time.Now().Before(b.ResetAt)
+}
+
+type bucket struct {
+	resetAt time.Time
+	hits    int
+	queued  int
+}
+
+// RateLimiter keeps track of 429 responses per route and holds back requests until the route resets
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	// MaxRetries is how many times a rate limited request is replayed. Zero means DefaultMaxRateLimitRetries
+	MaxRetries int
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *RateLimiter) maxRetries() int {
+	if l.MaxRetries > 0 {
+		return l.MaxRetries
+	}
+
+	return DefaultMaxRateLimitRetries
+}
+
+func (l *RateLimiter) get(route string) *bucket {
+	b, ok := l.buckets[route]
+	if !ok {
+		b = &bucket{}
+		l.buckets[route] = b
+	}
+
+	return b
+}
+
+// Wait blocks until the route is allowed to send requests again, or the context is done
+func (l *RateLimiter) Wait(ctx context.Context, route string) error {
+	for {
+		l.mu.Lock()
+		b := l.get(route)
+		wait := time.Until(b.resetAt)
+		if wait <= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+		b.queued++
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.dequeue(route)
+			return ctx.Err()
+		case <-timer.C:
+			l.dequeue(route)
+		}
+	}
+}
+
+func (l *RateLimiter) dequeue(route string) {
+	l.mu.Lock()
+	l.get(route).queued--
+	l.mu.Unlock()
+}
+
+// Limit marks the route as limited for the given duration
+func (l *RateLimiter) Limit(route string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.get(route)
+	b.hits++
+
+	resetAt := time.Now().Add(d)
+	if resetAt.After(b.resetAt) {
+		b.resetAt = resetAt
+	}
+}
+
+// Buckets returns the state of every route the limiter has seen, sorted by route
+func (l *RateLimiter) Buckets() []Bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets := make([]Bucket, 0, len(l.buckets))
+	for route, b := range l.buckets {
+		buckets = append(buckets, Bucket{
+			Route:   route,
+			ResetAt: b.resetAt,
+			Hits:    b.hits,
+			Queued:  b.queued,
+		})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Route < buckets[j].Route
+	})
+
+	return buckets
+}
+
+// Route returns the bucket key for a request. The first ID in the path (the server or channel)
+// is kept so that limits on one server don't hold back another, every other ID is replaced by ":id".
+//
+// /api/v1/servers/abc/members/def -> /servers/abc/members/:id
+func Route(method, rawURL string) string {
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+
+	path = strings.TrimPrefix(path, "/api/v1")
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range segments {
+		// Segments alternate between resources and IDs: servers/{id}/members/{id}
+		if i%2 == 1 && i > 1 {
+			segments[i] = ":id"
+		}
+	}
+
+	return method + " /" + strings.Join(segments, "/")
+}
+
+// RetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date
+func RetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return defaultRetryAfter
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+
+		return 0
+	}
+
+	return defaultRetryAfter
+}
@@ -0,0 +1,224 @@
+// Package rest contains the HTTP transport shared by the guildedgo client and pkg/client
+package rest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultBaseURL is the Guilded REST API that all endpoints are built on
+	DefaultBaseURL = "https://www.guilded.gg/api/v1"
+
+	// DefaultWebsocketURL is the Guilded gateway
+	DefaultWebsocketURL = "wss://www.guilded.gg/websocket/v1"
+
+	// DefaultUserAgent is sent with every request unless another one is configured
+	DefaultUserAgent = "guildedgo (https://github.com/itschip/guildedgo)"
+)
+
+// Client sends requests to the Guilded REST API
+type Client struct {
+	HTTPClient  *http.Client
+	RateLimiter *RateLimiter
+
+	// BaseURL replaces DefaultBaseURL in request URLs, e.g. to talk to a local stand-in for Guilded
+	BaseURL string
+
+	// UserAgent is sent with every request, DefaultUserAgent if empty
+	UserAgent string
+
+	// RetryPolicy is applied to transport errors and retryable responses. Nil disables retries
+	RetryPolicy *RetryPolicy
+
+	// Logger receives requests at debug level and rate limits and retries at warn level, slog.Default() if nil
+	Logger *slog.Logger
+
+	mu         sync.RWMutex
+	middleware []Middleware
+}
+
+func New() *Client {
+	return &Client{
+		HTTPClient:  http.DefaultClient,
+		RateLimiter: NewRateLimiter(),
+	}
+}
+
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+
+	// The request body. It is kept as bytes so that the request can be replayed
+	Body []byte
+}
+
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Do sends the request. A 429 response marks the route as limited and the request is queued
+// until the route resets, up to the rate limiter's MaxRetries. After that the 429 response is returned.
+// Other failures are retried according to the RetryPolicy, the last response or error is returned.
+func (c *Client) Do(ctx context.Context, r *Request) (*Response, error) {
+	route := Route(r.Method, r.URL)
+
+	rateLimited := 0
+	attempt := 1
+
+	for {
+		err := c.RateLimiter.Wait(ctx, route)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := c.send(ctx, r)
+		c.log(ctx, route, start, resp, err)
+
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter := RetryAfter(resp.Header)
+			c.RateLimiter.Limit(route, retryAfter)
+
+			if rateLimited >= c.RateLimiter.maxRetries() {
+				c.logger().WarnContext(ctx, "Giving up on rate limited request", "route", route, "retries", rateLimited)
+				return resp, nil
+			}
+
+			c.logger().WarnContext(ctx, "Rate limited", "route", route, "retry_after", retryAfter)
+
+			rateLimited++
+			continue
+		}
+
+		if !c.RetryPolicy.shouldRetry(attempt, r.Method, resp, err) {
+			return resp, err
+		}
+
+		delay := c.RetryPolicy.delay(attempt, resp)
+		c.logger().WarnContext(ctx, "Retrying request", "route", route, "attempt", attempt, "delay", delay)
+
+		err = sleep(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+
+		attempt++
+	}
+}
+
+func (c *Client) log(ctx context.Context, route string, start time.Time, resp *Response, err error) {
+	if err != nil {
+		c.logger().DebugContext(ctx, "Request failed", "route", route, "duration", time.Since(start), "error", err)
+		return
+	}
+
+	c.logger().DebugContext(ctx, "Request", "route", route, "status", resp.StatusCode, "duration", time.Since(start))
+}
+
+func (c *Client) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+
+	return slog.Default()
+}
+
+func (c *Client) send(ctx context.Context, r *Request) (*Response, error) {
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, c.url(r.URL), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range r.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent())
+	}
+
+	resp, err := c.handler()(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, nil
+}
+
+// url points rawURL at BaseURL. Endpoints are always built on DefaultBaseURL
+func (c *Client) url(rawURL string) string {
+	if c.BaseURL == "" || c.BaseURL == DefaultBaseURL {
+		return rawURL
+	}
+
+	if strings.HasPrefix(rawURL, DefaultBaseURL) {
+		return strings.TrimSuffix(c.BaseURL, "/") + strings.TrimPrefix(rawURL, DefaultBaseURL)
+	}
+
+	return rawURL
+}
+
+func (c *Client) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+
+	return DefaultUserAgent
+}
+
+// HTTPClient returns the client to send requests with: httpClient if set, otherwise a client
+// using transport if set, otherwise http.DefaultClient
+func HTTPClient(httpClient *http.Client, transport http.RoundTripper) *http.Client {
+	if httpClient != nil {
+		return httpClient
+	}
+
+	if transport != nil {
+		return &http.Client{Transport: transport}
+	}
+
+	return http.DefaultClient
+}
+
+// WebsocketDialer returns a dialer for the gateway that uses the proxy and TLS settings
+// of the client's transport, if it is an *http.Transport
+func WebsocketDialer(httpClient *http.Client) *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+
+	if t, ok := httpClient.Transport.(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+		dialer.TLSClientConfig = t.TLSClientConfig
+	}
+
+	return &dialer
+}
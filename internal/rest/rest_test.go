@@ -0,0 +1,367 @@
+package rest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo/internal/rest"
+)
+
+func TestRoute(t *testing.T) {
+	tests := map[string]string{
+		"https://www.guilded.gg/api/v1/servers/abc/members/def":       "DELETE /servers/abc/members/:id",
+		"https://www.guilded.gg/api/v1/channels/abc/topics/1/pin":     "DELETE /channels/abc/topics/:id/pin",
+		"https://www.guilded.gg/api/v1/channels/abc/messages?limit=5": "DELETE /channels/abc/messages",
+	}
+
+	for url, want := range tests {
+		got := rest.Route(http.MethodDelete, url)
+		if got != want {
+			t.Errorf("Route(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestDoRetriesRateLimitedRequests(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	c := rest.New()
+
+	resp, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodDelete,
+		URL:    srv.URL + "/servers/abc/members/def",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	buckets := c.RateLimiter.Buckets()
+	if len(buckets) != 1 || buckets[0].Hits != 1 {
+		t.Errorf("expected one bucket with one hit, got %+v", buckets)
+	}
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := rest.New()
+	c.RateLimiter.MaxRetries = 2
+
+	resp, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodGet,
+		URL:    srv.URL + "/servers/abc",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", resp.StatusCode)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := rest.New()
+	c.RetryPolicy = rest.DefaultRetryPolicy()
+	c.RetryPolicy.BaseDelay = time.Millisecond
+
+	resp, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodGet,
+		URL:    srv.URL + "/servers/abc",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoStopsWhenContextIsDone(t *testing.T) {
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/limited":
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/slow":
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := rest.New()
+	c.RetryPolicy = rest.DefaultRetryPolicy()
+	c.RetryPolicy.BaseDelay = time.Minute
+	c.RetryPolicy.MaxDelay = 0
+	c.RetryPolicy.Jitter = 0
+
+	// Waiting for the rate limit to reset, waiting to retry, and waiting for the response
+	for _, path := range []string{"/limited", "/unavailable", "/slow"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+		start := time.Now()
+		_, err := c.Do(ctx, &rest.Request{Method: http.MethodGet, URL: srv.URL + path})
+		cancel()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected the context error, got %v", path, err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: expected the request to stop with the context, took %s", path, elapsed)
+		}
+	}
+}
+
+func TestDoDoesNotReplayPost(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := rest.New()
+	c.RetryPolicy = rest.DefaultRetryPolicy()
+	c.RetryPolicy.BaseDelay = time.Millisecond
+
+	resp, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodPost,
+		URL:    srv.URL + "/channels/abc/messages",
+		Body:   []byte(`{"content":"hello"}`),
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", resp.StatusCode)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	req := &rest.Request{Method: http.MethodGet, URL: "https://www.guilded.gg/api/v1/servers/abc/members/def"}
+	resp := &rest.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"X-Request-Id": []string{"req-1"}},
+		Body:       []byte(`{"code":"NotFound","message":"Member not found","meta":{"userId":"def"}}`),
+	}
+
+	err := fmt.Errorf("failed to get member: %w", rest.CheckResponse(req, resp))
+
+	var apiErr *rest.APIError
+	if !errors.As(err, &apiErr) {
+		t.Error("expected an *APIError")
+		t.FailNow()
+	}
+
+	if apiErr.Code != "NotFound" || apiErr.Message != "Member not found" || apiErr.RequestID != "req-1" {
+		t.Errorf("unexpected error fields: %+v", apiErr)
+	}
+
+	if apiErr.Meta["userId"] != "def" {
+		t.Errorf("expected meta userId def, got %v", apiErr.Meta["userId"])
+	}
+
+	if !rest.HasStatus(err, http.StatusNotFound) {
+		t.Error("expected HasStatus to match 404")
+	}
+
+	resp.StatusCode = http.StatusNoContent
+	if rest.CheckResponse(req, resp) != nil {
+		t.Error("expected no error for 204")
+	}
+}
+
+func TestDoUsesBaseURLAndUserAgent(t *testing.T) {
+	var path, userAgent string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		userAgent = r.UserAgent()
+	}))
+	defer srv.Close()
+
+	c := rest.New()
+	c.BaseURL = srv.URL + "/v1"
+	c.UserAgent = "test-bot/1.0"
+
+	_, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodGet,
+		URL:    rest.DefaultBaseURL + "/servers/abc",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if path != "/v1/servers/abc" {
+		t.Errorf("expected path /v1/servers/abc, got %s", path)
+	}
+
+	if userAgent != "test-bot/1.0" {
+		t.Errorf("expected user agent test-bot/1.0, got %s", userAgent)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var header string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Test")
+	}))
+	defer srv.Close()
+
+	c := rest.New()
+	c.RetryPolicy = rest.DefaultRetryPolicy()
+	c.RetryPolicy.BaseDelay = time.Millisecond
+
+	var order []string
+	c.Use(func(next rest.Handler) rest.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			order = append(order, "outer")
+			req.Header.Set("X-Test", "injected")
+			return next(req)
+		}
+	})
+
+	// Fail the first attempt before it reaches the server
+	failed := false
+	c.Use(func(next rest.Handler) rest.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			order = append(order, "inner")
+			if !failed {
+				failed = true
+				return nil, errors.New("injected fault")
+			}
+			return next(req)
+		}
+	})
+
+	_, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodGet,
+		URL:    srv.URL + "/servers/abc",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if header != "injected" {
+		t.Errorf("expected injected header, got %q", header)
+	}
+
+	want := "outer,inner,outer,inner"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("expected middleware order %s, got %s", want, got)
+	}
+}
+
+func TestDoLogs(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+
+	c := rest.New()
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := c.Do(context.Background(), &rest.Request{
+		Method: http.MethodDelete,
+		URL:    srv.URL + "/servers/abc/members/def",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		`level=WARN msg="Rate limited" route="DELETE /servers/abc/members/:id"`,
+		`level=DEBUG msg=Request route="DELETE /servers/abc/members/:id" status=204`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected the logs to contain %s, got:\n%s", want, logs)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides if and when a failed request is sent again.
+// Failed means a transport error or a response with one of RetryableStatusCodes.
+// Rate limited (429) requests are handled by the RateLimiter and don't count towards MaxAttempts.
+type RetryPolicy struct {
+	// The total number of attempts, including the first one. Values below 2 disable retries
+	MaxAttempts int
+
+	// The delay before the first retry. It doubles with every following attempt
+	BaseDelay time.Duration
+
+	// The upper bound for the delay between attempts. Zero means no bound
+	MaxDelay time.Duration
+
+	// The fraction (0 to 1) of each delay that is randomized, so that clients don't retry in lockstep
+	Jitter float64
+
+	// Response status codes that are retried
+	RetryableStatusCodes []int
+
+	// Methods that are retried. Requests with other methods (POST and PATCH by default) are only
+	// retried if they never reached Guilded, e.g. when the connection could not be established,
+	// so that they are not applied twice
+	RetryableMethods []string
+}
+
+// DefaultRetryPolicy retries idempotent requests up to 3 times on 5xx responses and transport errors
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableMethods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions,
+			http.MethodPut,
+			http.MethodDelete,
+		},
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) retryableMethod(method string) bool {
+	for _, m := range p.RetryableMethods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldRetry reports whether attempt (starting at 1) may be followed by another one
+func (p *RetryPolicy) shouldRetry(attempt int, method string, resp *Response, err error) bool {
+	if attempt >= p.maxAttempts() {
+		return false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+
+		return p.retryableMethod(method) || !sent(err)
+	}
+
+	return p.retryableStatus(resp.StatusCode) && p.retryableMethod(method)
+}
+
+// delay returns how long to wait before the attempt following the given one
+func (p *RetryPolicy) delay(attempt int, resp *Response) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+
+	// Guilded may tell us when it expects to be back
+	if resp != nil && resp.Header.Get("Retry-After") != "" {
+		if after := RetryAfter(resp.Header); after > d {
+			d = after
+		}
+	}
+
+	return d
+}
+
+// sent reports whether the request may have reached the server before err occurred.
+// Only errors from dialing are known to happen before anything is written.
+func sent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
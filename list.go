@@ -1,7 +1,7 @@
 package guildedgo
 
 import (
-	"errors"
+	"fmt"
 )
 
 type ListItem struct {
@@ -94,7 +94,7 @@ func (service *listService) CreateListItem(channelID string, listObject ListObje
 	var listItem ListItem
 	err := service.client.PostRequestV2(service.endpoints.Default(channelID), &listObject, &listItem)
 	if err != nil {
-		return nil, errors.New("Error creating list item: " + err.Error())
+		return nil, fmt.Errorf("Error creating list item: %w", err)
 	}
 
 	return &listItem, nil
@@ -104,7 +104,7 @@ func (service *listService) GetChannelListItems(channelID string) ([]ListItemSum
 	var listItems []ListItemSummary
 	err := service.client.GetRequestV2(service.endpoints.Default(channelID), &listItems)
 	if err != nil {
-		return nil, errors.New("Error getting channel list items: " + err.Error())
+		return nil, fmt.Errorf("Error getting channel list items: %w", err)
 	}
 
 	return listItems, nil
@@ -114,7 +114,7 @@ func (service *listService) GetListItem(channelID, listItemID string) (*ListItem
 	var listItem ListItem
 	err := service.client.GetRequestV2(service.endpoints.Get(channelID, listItemID), &listItem)
 	if err != nil {
-		return nil, errors.New("Error getting list item: " + err.Error())
+		return nil, fmt.Errorf("Error getting list item: %w", err)
 	}
 
 	return &listItem, nil
@@ -124,7 +124,7 @@ func (service *listService) UpdateListItem(channelID, listItemID string, listObj
 	var listItem ListItem
 	err := service.client.PutRequestV2(service.endpoints.Get(channelID, listItemID), &listObject, &listItem)
 	if err != nil {
-		return nil, errors.New("Error updating list item: " + err.Error())
+		return nil, fmt.Errorf("Error updating list item: %w", err)
 	}
 
 	return &listItem, nil
@@ -133,7 +133,7 @@ func (service *listService) UpdateListItem(channelID, listItemID string, listObj
 func (service *listService) DeleteListItem(channelID, listItemID string) error {
 	_, err := service.client.DeleteRequest(service.endpoints.Get(channelID, listItemID))
 	if err != nil {
-		return errors.New("Error deleting list item: " + err.Error())
+		return fmt.Errorf("Error deleting list item: %w", err)
 	}
 
 	return nil
@@ -142,7 +142,7 @@ func (service *listService) DeleteListItem(channelID, listItemID string) error {
 func (service *listService) CompleteListItem(channelID, listItemID string) error {
 	_, err := service.client.PostRequest(service.endpoints.Complete(channelID, listItemID), nil)
 	if err != nil {
-		return errors.New("Error completing list item: " + err.Error())
+		return fmt.Errorf("Error completing list item: %w", err)
 	}
 
 	return nil
@@ -151,7 +151,7 @@ func (service *listService) CompleteListItem(channelID, listItemID string) error
 func (service *listService) UncompleteListItem(channelID, listItemID string) error {
 	_, err := service.client.DeleteRequest(service.endpoints.Complete(channelID, listItemID))
 	if err != nil {
-		return errors.New("Error uncompleting list item: " + err.Error())
+		return fmt.Errorf("Error uncompleting list item: %w", err)
 	}
 
 	return nil
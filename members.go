@@ -2,9 +2,7 @@ package guildedgo
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
 )
 
 type ServerMember struct {
@@ -117,7 +115,7 @@ func (service *membersService) DeleteMemberNickname(userId string) error {
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to delete member nickname. Error: %s", err.Error()))
+		return fmt.Errorf("Failed to delete member nickname. Error: %w", err)
 	}
 
 	return nil
@@ -129,8 +127,7 @@ func (service *membersService) GetServerMember(serverId string, userId string) (
 	var member ServerMemberResponse
 	err := service.client.GetRequestV2(endpoint, &member)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, errors.New(fmt.Sprintf("Failed to get member. Error: %s", err.Error()))
+		return nil, fmt.Errorf("Failed to get member. Error: %w", err)
 	}
 
 	return &member.Member, nil
@@ -141,7 +138,7 @@ func (service *membersService) KickMember(userId string) error {
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Failed to kick member. Error: %s", err.Error()))
+		return fmt.Errorf("Failed to kick member. Error: %w", err)
 	}
 
 	return nil
@@ -182,8 +179,7 @@ func (service *membersService) UnbanMember(userId string) error {
 
 	_, err := service.client.DeleteRequest(endpoint)
 	if err != nil {
-		log.Fatalln(err.Error())
-		return err
+		return fmt.Errorf("Failed to unban member. Error: %w", err)
 	}
 
 	return nil
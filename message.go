@@ -61,7 +61,7 @@ const (
 // bold, strikethrough, underline, inline code, block code, reaction, and mention.
 type ChatEmbed struct {
 	// Main header of the embed (max length 256)
-	Title string `json:"embed,omitempty"`
+	Title string `json:"title,omitempty"`
 
 	// Subtext of the embed (max length 2048)
 	Description string `json:"description,omitempty"`
@@ -111,7 +111,7 @@ type ChatEmbedImage struct {
 
 type ChatEmbedAuthor struct {
 	// Name of the author (max length 256)
-	Name string `jsojn:"name,omitempty"`
+	Name string `json:"name,omitempty"`
 
 	// URL to linkify the author's name field (max length 1024; regex ^(?!attachment))
 	URL string `json:"url,omitempty"`
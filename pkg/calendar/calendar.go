@@ -59,6 +59,23 @@ type CalendarEventRsvp struct {
 	UpdatedAt       string `json:"updatedAt,omitempty"`
 }
 
+type Comment struct {
+	ID              int             `json:"id"`
+	Content         string          `json:"content"`
+	CreatedAt       string          `json:"createdAt"`
+	UpdatedAt       string          `json:"updatedAt,omitempty"`
+	CalendarEventID int             `json:"calendarEventId"`
+	ChannelID       string          `json:"channelId"`
+	CreatedBy       string          `json:"createdBy"`
+	Mentions        channel.Mention `json:"mentions,omitempty"`
+}
+
+type Series struct {
+	ID        string `json:"id"`
+	ServerID  string `json:"serverId"`
+	ChannelID string `json:"channelId"`
+}
+
 type CreateParams struct {
 	// Name of the event (min length 1; max length 60)
 	Name string `json:"name"`
@@ -1,19 +1,40 @@
 package client
 
 import (
+	"context"
 	"github.com/gorilla/websocket"
+	"github.com/itschip/guildedgo/internal/gateway"
+	"github.com/itschip/guildedgo/internal/rest"
+	"log/slog"
+	"net/http"
 	"os"
 	"sync"
+	"time"
 )
 
 type Client struct {
 	sync.RWMutex
-	wsMutex   sync.Mutex
-	conn      *websocket.Conn
-	interrupt chan os.Signal
-	listening chan struct{}
-	events    map[string][]event
-	commands  map[string]Command
+	session    *gateway.Session
+	interrupt  chan os.Signal
+	dispatcher *gateway.Dispatcher
+	raw        *gateway.Dispatcher
+	pool       *gateway.Pool
+	commands   map[string]Command
+	rest       *rest.Client
+	ctx        context.Context
+	timeout    time.Duration
+
+	shutdownTimeout  time.Duration
+	missedHeartbeats int
+	logger           *slog.Logger
+
+	websocketURL  string
+	lastMessageID string
+	dialer        *websocket.Dialer
+	userAgent     string
+
+	// parent is the client WithContext was called on, nil for clients made by New
+	parent *Client
 
 	ServerID string
 	Token    string
@@ -22,6 +43,92 @@ type Client struct {
 type Config struct {
 	Token    string
 	ServerID string
+
+	// RetryPolicy controls how failed requests are retried. Nil disables retries,
+	// DefaultRetryPolicy returns a policy that retries idempotent requests on 5xx responses
+	RetryPolicy *RetryPolicy
+
+	// HTTPClient is used for all REST requests. Defaults to a client using Transport,
+	// or http.DefaultClient if Transport is nil too
+	HTTPClient *http.Client
+
+	// Transport is used for REST requests when HTTPClient is nil, e.g. to add a proxy
+	Transport http.RoundTripper
+
+	// BaseURL overrides the REST API URL, https://www.guilded.gg/api/v1 by default
+	BaseURL string
+
+	// WebsocketURL overrides the gateway URL, wss://www.guilded.gg/websocket/v1 by default
+	WebsocketURL string
+
+	// UserAgent is sent with every request and when connecting to the gateway
+	UserAgent string
+
+	// Timeout bounds every request, including rate limit and retry waits, that is made without
+	// a context deadline. Zero means DefaultTimeout, a negative value disables the timeout
+	Timeout time.Duration
+
+	// LastMessageID resumes a previous gateway session, e.g. from before a restart.
+	// Events Guilded sent since then are replayed, see Client.LastMessageID
+	LastMessageID string
+
+	// Workers is the number of goroutines calling event handlers, DefaultWorkers by default.
+	// Events of the same channel, or of the same server for events without a channel, are always handled in order
+	Workers int
+
+	// QueueSize is the number of events buffered per worker, DefaultQueueSize by default
+	QueueSize int
+
+	// QueuePolicy decides what happens to events when the queue of a worker is full, QueueBlock by default
+	QueuePolicy QueuePolicy
+
+	// OnHandlerError is called when an event or command handler panics or returns an error.
+	// The client keeps running either way. Errors are logged if it's nil
+	OnHandlerError func(err *HandlerError)
+
+	// ShutdownTimeout is how long Close and Run wait for Guilded to acknowledge the close frame
+	// and for event handlers to finish, DefaultShutdownTimeout by default
+	ShutdownTimeout time.Duration
+
+	// MissedHeartbeats is how many heartbeats Guilded may leave unanswered before the client reconnects,
+	// DefaultMissedHeartbeats by default
+	MissedHeartbeats int
+
+	// Logger receives the client's logs, slog.Default() if nil. Connection problems are logged at warn level,
+	// failed handlers and undecodable events at error level, requests and connection changes at debug level
+	Logger *slog.Logger
+}
+
+const (
+	DefaultWorkers   = gateway.DefaultWorkers
+	DefaultQueueSize = gateway.DefaultQueueSize
+
+	DefaultShutdownTimeout  = 10 * time.Second
+	DefaultMissedHeartbeats = gateway.DefaultMissedHeartbeats
+)
+
+// QueuePolicy decides what happens to events when event handlers can't keep up
+type QueuePolicy = gateway.Policy
+
+const (
+	// QueueBlock stops reading from the gateway until there is room in the queue.
+	// Handlers that are too slow for too long can make the connection time out and reconnect.
+	QueueBlock = gateway.Block
+
+	// QueueDrop discards events that don't fit in the queue
+	QueueDrop = gateway.Drop
+)
+
+// DefaultTimeout is the request timeout used when Config.Timeout is zero
+const DefaultTimeout = 10 * time.Second
+
+// RetryPolicy decides if and when a failed request is sent again
+type RetryPolicy = rest.RetryPolicy
+
+// DefaultRetryPolicy retries idempotent requests up to 3 times on 5xx responses and transport errors,
+// with exponential backoff starting at 500ms
+func DefaultRetryPolicy() *RetryPolicy {
+	return rest.DefaultRetryPolicy()
 }
 
 const (
@@ -29,9 +136,110 @@ const (
 )
 
 func New(config Config) *Client {
+	c := &Client{
+		ServerID:   config.ServerID,
+		Token:      config.Token,
+		dispatcher: gateway.NewDispatcher(),
+		raw:        gateway.NewDispatcher(),
+		rest:       rest.New(),
+		timeout:    config.Timeout,
+		pool: gateway.NewPool(gateway.PoolConfig{
+			Workers:   config.Workers,
+			QueueSize: config.QueueSize,
+			Policy:    config.QueuePolicy,
+		}),
+	}
+
+	if c.timeout == 0 {
+		c.timeout = DefaultTimeout
+	}
+
+	c.shutdownTimeout = config.ShutdownTimeout
+	if c.shutdownTimeout <= 0 {
+		c.shutdownTimeout = DefaultShutdownTimeout
+	}
+
+	c.missedHeartbeats = config.MissedHeartbeats
+
+	c.logger = config.Logger
+	if c.logger == nil {
+		c.logger = slog.Default()
+	}
+
+	c.dispatcher.OnError = config.OnHandlerError
+	c.dispatcher.Logger = c.logger
+	c.raw.OnError = config.OnHandlerError
+	c.raw.Logger = c.logger
+
+	c.rest.RetryPolicy = config.RetryPolicy
+	c.rest.HTTPClient = rest.HTTPClient(config.HTTPClient, config.Transport)
+	c.rest.BaseURL = config.BaseURL
+	c.rest.UserAgent = config.UserAgent
+	c.rest.Logger = c.logger
+
+	c.lastMessageID = config.LastMessageID
+	c.websocketURL = config.WebsocketURL
+	if c.websocketURL == "" {
+		c.websocketURL = rest.DefaultWebsocketURL
+	}
+
+	c.dialer = rest.WebsocketDialer(c.rest.HTTPClient)
+
+	c.userAgent = config.UserAgent
+	if c.userAgent == "" {
+		c.userAgent = rest.DefaultUserAgent
+	}
+
+	return c
+}
+
+// WithContext returns a client whose requests use ctx for cancellation and deadlines.
+// Since every pkg function takes the client, this gives all of them a context:
+//
+//	m, err := member.Find(c.WithContext(ctx), serverID, userID)
+//
+// The returned client shares the transport, rate limits and event handlers with r.
+// It is meant for requests: handlers registered through it receive r, so they don't
+// inherit ctx, and Open, Run, Close and Stats act on the connection of r.
+func (r *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("client: nil context")
+	}
+
 	return &Client{
-		ServerID: config.ServerID,
-		Token:    config.Token,
-		events:   make(map[string][]event),
+		ServerID:   r.ServerID,
+		Token:      r.Token,
+		dispatcher: r.dispatcher,
+		raw:        r.raw,
+		pool:       r.pool,
+		commands:   r.commands,
+		rest:       r.rest,
+		ctx:        ctx,
+		timeout:    r.timeout,
+		logger:     r.logger,
+
+		websocketURL: r.websocketURL,
+		dialer:       r.dialer,
+		userAgent:    r.userAgent,
+
+		parent: r.base(),
 	}
 }
+
+// base returns the client made by New, which owns the connection and is passed to handlers
+func (r *Client) base() *Client {
+	if r.parent != nil {
+		return r.parent
+	}
+
+	return r
+}
+
+// Context returns the client's context, set by WithContext. It defaults to context.Background
+func (r *Client) Context() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+
+	return context.Background()
+}
@@ -9,22 +9,36 @@ type CommandsBuilder struct {
 type Command struct {
 	CommandName string
 	Action      func(client *Client, v *socketevent.ChatMessageCreated)
+
+	// Run is called instead of Action if it is set. An error it returns is passed to Config.OnHandlerError
+	Run func(client *Client, v *socketevent.ChatMessageCreated) error
 }
 
 func (r *Client) AddCommands(builder *CommandsBuilder) {
 	// Is this the best way to do this? I'm not sure. - Thanks, Copilot
 	for _, command := range builder.Commands {
+		if command.Run != nil {
+			r.handleCommand(command.CommandName, command.Run)
+			continue
+		}
+
 		r.Command(command.CommandName, command.Action)
 	}
 }
 
-func (r *Client) Command(cmd string, callback func(client *Client, v *socketevent.ChatMessageCreated)) {
-	r.On("ChatMessageCreated", func(client *Client, v any) {
-		data, ok := v.(*socketevent.ChatMessageCreated)
-		if ok {
-			if data.Message.Content == cmd {
-				callback(client, data)
-			}
+func (r *Client) Command(cmd string, callback func(client *Client, v *socketevent.ChatMessageCreated)) *Subscription {
+	return r.handleCommand(cmd, func(client *Client, v *socketevent.ChatMessageCreated) error {
+		callback(client, v)
+		return nil
+	})
+}
+
+func (r *Client) handleCommand(cmd string, handler func(client *Client, v *socketevent.ChatMessageCreated) error) *Subscription {
+	return HandleEvent(r, func(client *Client, data *socketevent.ChatMessageCreated) error {
+		if data.Message.Content != cmd {
+			return nil
 		}
+
+		return handler(client, data)
 	})
 }
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/itschip/guildedgo/internal/rest"
+)
+
+// APIError is returned when Guilded responds with a non 2xx status code.
+// Use errors.As to get it from the errors returned by the services:
+//
+//	var apiErr *APIError
+//	if errors.As(err, &apiErr) {
+//		log.Println(apiErr.StatusCode, apiErr.Code, apiErr.RequestID)
+//	}
+type APIError = rest.APIError
+
+// IsBadRequest reports whether err is caused by a 400 response
+func IsBadRequest(err error) bool {
+	return rest.HasStatus(err, http.StatusBadRequest)
+}
+
+// IsUnauthorized reports whether err is caused by a 401 response, usually an invalid token
+func IsUnauthorized(err error) bool {
+	return rest.HasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is caused by a 403 response, usually missing permissions
+func IsForbidden(err error) bool {
+	return rest.HasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether err is caused by a 404 response
+func IsNotFound(err error) bool {
+	return rest.HasStatus(err, http.StatusNotFound)
+}
+
+// IsRateLimited reports whether err is caused by a 429 response that was still limited after all retries
+func IsRateLimited(err error) bool {
+	return rest.HasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsServerError reports whether err is caused by a 5xx response
+func IsServerError(err error) bool {
+	return rest.IsServerError(err)
+}
@@ -2,24 +2,167 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"github.com/itschip/guildedgo/internal/gateway"
 	"reflect"
 )
 
-type event struct {
-	Callback func(*Client, any)
-	Type     *interface{}
+// Subscription is a registered event handler, call Remove to unregister it
+type Subscription = gateway.Subscription
+
+// HandlerError is a panic in an event handler, or an error it returned. See Config.OnHandlerError
+type HandlerError = gateway.HandlerError
+
+// On listens to an event. e is either the event name, e.g. "ChatMessageCreated",
+// or a value of the event type, e.g. &event.ChatMessageCreated{}.
+// It panics if e is neither.
+func (r *Client) On(e any, callback func(client *Client, v any)) *Subscription {
+	return r.Handle(e, func(client *Client, v any) error {
+		callback(client, v)
+		return nil
+	})
+}
+
+// Handle listens to an event like On, an error returned by handler is passed to Config.OnHandlerError
+func (r *Client) Handle(e any, handler func(client *Client, v any) error) *Subscription {
+	base := r.base()
+	return r.dispatcher.Add(eventName(e), func(name string, v any) error {
+		return handler(base, v)
+	})
+}
+
+// Once listens to the next occurrence of an event only, see On for e
+func (r *Client) Once(e any, callback func(client *Client, v any)) *Subscription {
+	base := r.base()
+	return r.dispatcher.Once(eventName(e), func(name string, v any) error {
+		callback(base, v)
+		return nil
+	})
+}
+
+// OnAny listens to every event, callback receives the event name along with the decoded value
+func (r *Client) OnAny(callback func(client *Client, event string, v any)) *Subscription {
+	base := r.base()
+	return r.dispatcher.Add(gateway.Any, func(name string, v any) error {
+		callback(base, name, v)
+		return nil
+	})
+}
+
+func eventName(e any) string {
+	name, ok := e.(string)
+	if ok {
+		return name
+	}
+
+	name, ok = eventRegistry.Name(reflect.TypeOf(e))
+	if !ok {
+		panic(fmt.Sprintf("client: %T is not an event", e))
+	}
+
+	return name
 }
 
-func (r *Client) On(e any, callback func(client *Client, v any)) {
-	eventName := reflect.TypeOf(e).String()
+// OnEvent listens to the event T is decoded from, e.g.
+//
+//	client.OnEvent(c, func(c *client.Client, e *event.ChatMessageCreated) {})
+//
+// It panics if T is not an event type.
+func OnEvent[T any](r *Client, callback func(client *Client, e *T)) *Subscription {
+	return HandleEvent(r, func(client *Client, e *T) error {
+		callback(client, e)
+		return nil
+	})
+}
 
-	r.events[eventName] = append(r.events[eventName], event{
-		Callback: callback,
+// HandleEvent listens to the event T is decoded from like OnEvent,
+// an error returned by handler is passed to Config.OnHandlerError
+func HandleEvent[T any](r *Client, handler func(client *Client, e *T) error) *Subscription {
+	return r.Handle(EventName[T](), func(client *Client, v any) error {
+		e, ok := v.(*T)
+		if !ok {
+			return nil
+		}
+
+		return handler(client, e)
 	})
 }
 
+// EventName returns the name of the event T is decoded from. It panics if T is not an event type
+func EventName[T any]() string {
+	name, ok := gateway.NameOf[T](eventRegistry)
+	if !ok {
+		panic(fmt.Sprintf("client: %T is not an event", *new(T)))
+	}
+
+	return name
+}
+
+// WaitFor blocks until an event named event arrives that predicate accepts, and returns it.
+// predicate may be nil to accept the next event. It returns ctx's error if ctx is done first, e.g. to wait for a reply:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+//	defer cancel()
+//
+//	v, err := c.WaitFor(ctx, "ChatMessageCreated", func(v any) bool {
+//		e := v.(*event.ChatMessageCreated)
+//		return e.Message.CreatedBy == userID && e.Message.ChannelID == channelID
+//	})
+//
+// Waiters get events as soon as they are received, before the event middleware and the handlers,
+// so a handler can wait for the next event of its own channel.
+func (r *Client) WaitFor(ctx context.Context, event string, predicate func(v any) bool) (any, error) {
+	return r.dispatcher.Wait(ctx, event, predicate)
+}
+
+// WaitForEvent waits for the event T is decoded from like WaitFor. It panics if T is not an event type
+func WaitForEvent[T any](ctx context.Context, r *Client, predicate func(e *T) bool) (*T, error) {
+	v, err := r.WaitFor(ctx, EventName[T](), func(v any) bool {
+		e, ok := v.(*T)
+		return ok && (predicate == nil || predicate(e))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*T), nil
+}
+
+// Collector gathers events until it reaches a limit, times out or a stop condition is met, see Client.Collect
+type Collector = gateway.Collector
+
+// CollectOptions decide which events a Collector gathers and when it stops
+type CollectOptions = gateway.CollectorConfig
+
+// CollectorStopReason is why a Collector stopped, see Collector.Reason
+type CollectorStopReason = gateway.StopReason
+
+const (
+	CollectorLimit     = gateway.StopLimit
+	CollectorTimeout   = gateway.StopTimeout
+	CollectorCondition = gateway.StopCondition
+	CollectorCancelled = gateway.StopCancelled
+)
+
+// Collect starts gathering the events named event that pass opts.Filter, e.g. the reactions to a message for a minute:
+//
+//	collector := c.Collect("ChannelMessageReactionCreated", client.CollectOptions{
+//		Filter: func(v any) bool {
+//			return v.(*event.ChannelMessageReactionCreated).Reaction.MessageID == messageID
+//		},
+//		Timeout: time.Minute,
+//	})
+//
+//	reactions, err := collector.Wait(ctx)
+//
+// The collector runs until it is stopped, so set at least one of Max, Timeout and Until, or call Stop.
+// Like WaitFor, it gets events as soon as they are received, so it can be started from a handler.
+func (r *Client) Collect(event string, opts CollectOptions) *Collector {
+	return r.dispatcher.Collect(event, opts)
+}
+
 func (r *Client) onEvent(msg []byte) {
 	var err error
 	reader := bytes.NewBuffer(msg)
@@ -29,18 +172,113 @@ func (r *Client) onEvent(msg []byte) {
 
 	err = decoder.Decode(&re)
 	if err != nil {
-		log.Println("Failed to decode raw event")
+		r.logger.Error("Failed to decode raw event", "error", err)
+		return
 	}
 
-	eventType := interfaces[re.T]
-	err = json.Unmarshal(re.Data, eventType)
-	if err != nil {
-		log.Printf("Failed to unmarshal event data for %q. Error: %s", re.T, err.Error())
+	// Waiters and collectors get the event right away, they may be waiting in a handler
+	// that holds up the events of its channel. Otherwise events are decoded by the worker pool
+	var v any
+	offered := false
+	if re.OP == gateway.OpEvent && r.dispatcher.Watched(re.T) {
+		v = r.decode(re)
+		r.dispatcher.Offer(re.T, v)
+		offered = true
 	}
 
-	// Is this smart? Probably not.
-	eventsCB := r.events[re.T]
-	for _, event := range eventsCB {
-		event.Callback(r, eventType)
+	// Events are handled by the worker pool, so slow handlers don't hold up the connection
+	r.pool.Submit(gateway.OrderingKey(re.Data), func() {
+		r.raw.Dispatch(re.T, re, re.Data)
+
+		// Resume and resync op codes are handled by the session
+		if re.OP != gateway.OpEvent {
+			return
+		}
+
+		if !offered {
+			v = r.decode(re)
+		}
+
+		r.emit(re.T, v, re.Data)
+	})
+}
+
+// decode returns a new value of the event, handlers may keep it or hand it to other goroutines.
+// Events that aren't modelled yet are returned as they are
+func (r *Client) decode(re *RawEvent) any {
+	v, err := eventRegistry.Decode(re.T, re.Data)
+	if err == gateway.ErrUnknownEvent {
+		return re
+	} else if err != nil {
+		r.logger.Error("Failed to decode event", "event", re.T, "channel_id", gateway.ChannelID(re.Data), "error", err)
 	}
+
+	return v
+}
+
+// OnRaw listens to every message received from the gateway, before it is decoded and passed
+// through the event middleware. Events of types the library doesn't know yet are also passed
+// to the regular handlers as *RawEvent, e.g. c.On("SomeNewEvent", ...).
+func (r *Client) OnRaw(callback func(client *Client, e *RawEvent)) *Subscription {
+	base := r.base()
+	return r.raw.Add(gateway.Any, func(name string, v any) error {
+		callback(base, v.(*RawEvent))
+		return nil
+	})
+}
+
+// EventHandler passes an event on to the next event middleware, or to the event handlers
+type EventHandler func(client *Client, event string, v any)
+
+// EventMiddleware runs before the handlers of every event, e.g. to log events or ignore those of other servers:
+//
+//	func(next client.EventHandler) client.EventHandler {
+//		return func(client *client.Client, event string, v any) {
+//			log.Println("received", event)
+//			next(client, event, v)
+//		}
+//	}
+//
+// It may change the event, pass on a different one, or not call next to keep the event from the handlers.
+type EventMiddleware func(next EventHandler) EventHandler
+
+// UseEvent appends event middleware to the chain. The first middleware added is the outermost one
+func (r *Client) UseEvent(mw ...EventMiddleware) {
+	base := r.base()
+	for _, m := range mw {
+		m := m
+
+		r.dispatcher.Use(func(next gateway.Next) gateway.Next {
+			h := m(func(client *Client, event string, v any) {
+				next(event, v)
+			})
+
+			return func(name string, v any) {
+				h(base, name, v)
+			}
+		})
+	}
+}
+
+// emit calls the callbacks registered for event. payload is the raw event data, if any
+func (r *Client) emit(event string, v any, payload json.RawMessage) {
+	r.dispatcher.Dispatch(event, v, payload)
+}
+
+// post queues event to be emitted by the worker pool. Connection state events share a worker, so they stay in order
+func (r *Client) post(event string, v any) {
+	r.dispatcher.Offer(event, v)
+
+	r.pool.Submit("", func() {
+		r.emit(event, v, nil)
+	})
+}
+
+// DispatchStats is a snapshot of the event queues, see Client.DispatchStats
+type DispatchStats = gateway.PoolStats
+
+// DispatchStats reports how many events are queued, handled and dropped, and how long
+// reading from the gateway was blocked by full queues
+func (r *Client) DispatchStats() DispatchStats {
+	return r.pool.Stats()
 }
@@ -0,0 +1,76 @@
+package client_test
+
+import (
+	"github.com/itschip/guildedgo/pkg/client"
+	socketevent "github.com/itschip/guildedgo/pkg/event"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func fakeGateway(t *testing.T, events ...string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"op":1,"d":{"heartbeatIntervalMs":22500}}`))
+
+		for _, e := range events {
+			conn.WriteMessage(websocket.TextMessage, []byte(e))
+		}
+
+		conn.ReadMessage()
+	}))
+}
+
+func TestOnResolvesEventTypes(t *testing.T) {
+	srv := fakeGateway(t, `{"op":0,"t":"ChatMessageCreated","s":"1","d":{"serverId":"server","message":{"id":"1","content":"hello"}}}`)
+	defer srv.Close()
+
+	c := client.New(client.Config{
+		Token:        "token",
+		ServerID:     "server",
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	received := make(chan string, 3)
+	c.On(&socketevent.ChatMessageCreated{}, func(client *client.Client, v any) {
+		received <- "pointer"
+	})
+	c.On(socketevent.ChatMessageCreated{}, func(client *client.Client, v any) {
+		received <- "value"
+	})
+	client.OnEvent(c, func(client *client.Client, e *socketevent.ChatMessageCreated) {
+		received <- e.Message.Content
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	want := []string{"pointer", "value", "hello"}
+	for _, w := range want {
+		select {
+		case got := <-received:
+			if got != w {
+				t.Errorf("expected %q, got %q", w, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for", w)
+			t.FailNow()
+		}
+	}
+}
@@ -8,14 +8,12 @@ import (
 	"fmt"
 	"io"
 	nethttp "net/http"
-	"time"
+
+	"github.com/itschip/guildedgo/internal/rest"
 )
 
-type apiError struct {
-	Code    string          `json:"code"`
-	Message string          `json:"message"`
-	Meta    json.RawMessage `json:"meta"`
-}
+// RateLimitBucket is the rate limit state of a single route
+type RateLimitBucket = rest.Bucket
 
 var (
 	ErrMarshal    = errors.New("failed to marshal data")
@@ -23,56 +21,74 @@ var (
 	ErrTimeout    = errors.New("request timed out")
 )
 
+// PerformRequest sends a request to the Guilded API using the client's context, see WithContext.
+// Rate limited requests are queued and retried once the route resets, see RateLimits.
 func (r *Client) PerformRequest(method, url string, data any) (io.ReadCloser, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
+	return r.PerformRequestWithContext(r.Context(), method, url, data)
+}
 
-	marshalData, err := json.Marshal(data)
-	if err != nil {
-		return nil, ErrMarshal
+// PerformRequestWithContext is PerformRequest with a context that cancels the request,
+// including rate limit and retry waits. If ctx has no deadline, the client's Timeout is applied.
+func (r *Client) PerformRequestWithContext(ctx context.Context, method, url string, data any) (io.ReadCloser, error) {
+	if _, ok := ctx.Deadline(); !ok && r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
 	}
 
-	var buf io.Reader
-	if data == nil {
-		buf = nil
-	} else {
-		buf = bytes.NewBuffer(marshalData)
-	}
+	var body []byte
+	if data != nil {
+		marshalData, err := json.Marshal(data)
+		if err != nil {
+			return nil, ErrMarshal
+		}
 
-	req, err := nethttp.NewRequestWithContext(ctx, method, url, buf)
-	if err != nil {
-		return nil, ErrNewRequest
+		body = marshalData
 	}
 
-	req.Header.Set("Authorization", "Bearer "+r.Token)
-	req.Header.Set("Content-Type", "application/json")
+	header := nethttp.Header{}
+	header.Set("Authorization", "Bearer "+r.Token)
+	header.Set("Content-Type", "application/json")
 
-	client := &nethttp.Client{}
-	resp, err := client.Do(req)
+	req := &rest.Request{
+		Method: method,
+		URL:    url,
+		Header: header,
+		Body:   body,
+	}
+
+	resp, err := r.rest.Do(ctx, req)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, ErrTimeout
+			return nil, fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
 		}
 
 		return nil, err
 	}
 
-	switch resp.StatusCode {
-	case nethttp.StatusOK, nethttp.StatusCreated:
-		return resp.Body, nil
-	default:
-		var apiErr apiError
-		json.NewDecoder(resp.Body).Decode(&apiErr)
-
-		var message string
-		if apiErr.Message != "" {
-			message = apiErr.Message
-		} else {
-			message = "no message"
-		}
-
-		return nil, fmt.Errorf("api error (%d, %s): %s. meta: %v", resp.StatusCode, apiErr.Code, message, apiErr.Meta)
+	err = rest.CheckResponse(req, resp)
+	if err != nil {
+		return nil, err
 	}
+
+	return io.NopCloser(bytes.NewReader(resp.Body)), nil
+}
+
+// Handler sends a single request, see Use
+type Handler = rest.Handler
+
+// Middleware wraps a Handler to observe or change requests and responses, see Use
+type Middleware = rest.Middleware
+
+// Use adds middleware around every request the client sends, e.g. for logging or metrics.
+// Middleware runs in the order it was added and once per attempt, so retries pass through it again.
+func (r *Client) Use(mw ...Middleware) {
+	r.rest.Use(mw...)
+}
+
+// RateLimits returns the rate limit state of every route the client has sent requests to
+func (r *Client) RateLimits() []RateLimitBucket {
+	return r.rest.RateLimiter.Buckets()
 }
 
 func (r *Client) Decode(data io.ReadCloser, v any) error {
@@ -1,46 +1,133 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/gorilla/websocket"
+	"github.com/itschip/guildedgo/internal/gateway"
 	socketevent "github.com/itschip/guildedgo/pkg/event"
-	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
-var interfaces = make(map[string]any)
+// eventRegistry maps gateway event names to the types they are decoded into
+var eventRegistry = gateway.NewRegistry()
 
 func init() {
-	interfaces["BotServerMembershipCreated"] = &socketevent.BotServerMembershipCreated{}
-	interfaces["BotServerMembershipDeleted"] = &socketevent.BotServerMembershipDeleted{}
-	interfaces["ChatMessageCreated"] = &socketevent.ChatMessageCreated{}
-	interfaces["ChatMessageUpdated"] = &socketevent.ChatMessageUpdated{}
-	interfaces["ChatMessageDeleted"] = &socketevent.ChatMessageDeleted{}
-	interfaces["ServerMemberJoined"] = &socketevent.ServerMemberJoined{}
-	interfaces["ServerMemberRemoved"] = &socketevent.ServerMemberRemoved{}
-	interfaces["ServerMemberBanned"] = &socketevent.ServerMemberBanned{}
-	interfaces["ServerMemberUnbanned"] = &socketevent.ServerMemberUnbanned{}
-	interfaces["ServerMemberUpdated"] = &socketevent.ServerMemberUpdated{}
-	interfaces["ServerRolesUpdated"] = &socketevent.ServerRolesUpdated{}
-	interfaces["ServerChannelCreated"] = &socketevent.ServerChannelCreated{}
-	interfaces["ServerChannelUpdated"] = &socketevent.ServerChannelUpdated{}
-	interfaces["ServerChannelDeleted"] = &socketevent.ServerChannelDeleted{}
-	interfaces["ServerMemberSocialLinkCreated"] = &socketevent.ServerMemberSocialLinkCreated{}
-	interfaces["ServerMemberSocialLinkUpdated"] = &socketevent.ServerMemberSocialLinkUpdated{}
-	interfaces["ServerMemberSocialLinkDeleted"] = &socketevent.ServerMemberSocialLinkDeleted{}
-	interfaces["ServerWebhookCreated"] = &socketevent.ServerWebhookCreated{}
-	interfaces["ServerWebhookUpdated"] = &socketevent.ServerWebhookUpdated{}
-	interfaces["ChannelArchived"] = &socketevent.ChannelArchived{}
-	interfaces["ChannelRestored"] = &socketevent.ChannelRestored{}
+	gateway.Register[socketevent.BotServerMembershipCreated](eventRegistry, "BotServerMembershipCreated")
+	gateway.Register[socketevent.BotServerMembershipDeleted](eventRegistry, "BotServerMembershipDeleted")
+	gateway.Register[socketevent.ChatMessageCreated](eventRegistry, "ChatMessageCreated")
+	gateway.Register[socketevent.ChatMessageUpdated](eventRegistry, "ChatMessageUpdated")
+	gateway.Register[socketevent.ChatMessageDeleted](eventRegistry, "ChatMessageDeleted")
+	gateway.Register[socketevent.ServerMemberJoined](eventRegistry, "ServerMemberJoined")
+	gateway.Register[socketevent.ServerMemberRemoved](eventRegistry, "ServerMemberRemoved")
+	gateway.Register[socketevent.ServerMemberBanned](eventRegistry, "ServerMemberBanned")
+	gateway.Register[socketevent.ServerMemberUnbanned](eventRegistry, "ServerMemberUnbanned")
+	gateway.Register[socketevent.ServerMemberUpdated](eventRegistry, "ServerMemberUpdated")
+	gateway.Register[socketevent.ServerRolesUpdated](eventRegistry, "ServerRolesUpdated")
+	gateway.Register[socketevent.ServerChannelCreated](eventRegistry, "ServerChannelCreated")
+	gateway.Register[socketevent.ServerChannelUpdated](eventRegistry, "ServerChannelUpdated")
+	gateway.Register[socketevent.ServerChannelDeleted](eventRegistry, "ServerChannelDeleted")
+	gateway.Register[socketevent.ServerMemberSocialLinkCreated](eventRegistry, "ServerMemberSocialLinkCreated")
+	gateway.Register[socketevent.ServerMemberSocialLinkUpdated](eventRegistry, "ServerMemberSocialLinkUpdated")
+	gateway.Register[socketevent.ServerMemberSocialLinkDeleted](eventRegistry, "ServerMemberSocialLinkDeleted")
+	gateway.Register[socketevent.ServerWebhookCreated](eventRegistry, "ServerWebhookCreated")
+	gateway.Register[socketevent.ServerWebhookUpdated](eventRegistry, "ServerWebhookUpdated")
+	gateway.Register[socketevent.ChannelArchived](eventRegistry, "ChannelArchived")
+	gateway.Register[socketevent.ChannelRestored](eventRegistry, "ChannelRestored")
+	gateway.Register[socketevent.DocCreated](eventRegistry, "DocCreated")
+	gateway.Register[socketevent.DocUpdated](eventRegistry, "DocUpdated")
+	gateway.Register[socketevent.DocDeleted](eventRegistry, "DocDeleted")
+	gateway.Register[socketevent.CalendarEventCreated](eventRegistry, "CalendarEventCreated")
+	gateway.Register[socketevent.CalendarEventUpdated](eventRegistry, "CalendarEventUpdated")
+	gateway.Register[socketevent.CalendarEventDeleted](eventRegistry, "CalendarEventDeleted")
+	gateway.Register[socketevent.CalendarEventRsvpUpdated](eventRegistry, "CalendarEventRsvpUpdated")
+	gateway.Register[socketevent.CalendarEventRsvpManyUpdated](eventRegistry, "CalendarEventRsvpManyUpdated")
+	gateway.Register[socketevent.CalendarEventRsvpDeleted](eventRegistry, "CalendarEventRsvpDeleted")
+	gateway.Register[socketevent.ForumTopicCreated](eventRegistry, "ForumTopicCreated")
+	gateway.Register[socketevent.ForumTopicUpdated](eventRegistry, "ForumTopicUpdated")
+	gateway.Register[socketevent.ForumTopicDeleted](eventRegistry, "ForumTopicDeleted")
+	gateway.Register[socketevent.ForumTopicPinned](eventRegistry, "ForumTopicPinned")
+	gateway.Register[socketevent.ForumTopicUnpinned](eventRegistry, "ForumTopicUnpinned")
+	gateway.Register[socketevent.ForumTopicLocked](eventRegistry, "ForumTopicLocked")
+	gateway.Register[socketevent.ForumTopicUnlocked](eventRegistry, "ForumTopicUnlocked")
+	gateway.Register[socketevent.ForumTopicCommentCreated](eventRegistry, "ForumTopicCommentCreated")
+	gateway.Register[socketevent.ForumTopicCommentUpdated](eventRegistry, "ForumTopicCommentUpdated")
+	gateway.Register[socketevent.ForumTopicCommentDeleted](eventRegistry, "ForumTopicCommentDeleted")
+	gateway.Register[socketevent.RoleCreated](eventRegistry, "RoleCreated")
+	gateway.Register[socketevent.RoleUpdated](eventRegistry, "RoleUpdated")
+	gateway.Register[socketevent.RoleDeleted](eventRegistry, "RoleDeleted")
+	gateway.Register[socketevent.GroupCreated](eventRegistry, "GroupCreated")
+	gateway.Register[socketevent.GroupUpdated](eventRegistry, "GroupUpdated")
+	gateway.Register[socketevent.GroupDeleted](eventRegistry, "GroupDeleted")
+	gateway.Register[socketevent.CategoryCreated](eventRegistry, "CategoryCreated")
+	gateway.Register[socketevent.CategoryUpdated](eventRegistry, "CategoryUpdated")
+	gateway.Register[socketevent.CategoryDeleted](eventRegistry, "CategoryDeleted")
+	gateway.Register[socketevent.ListItemCreated](eventRegistry, "ListItemCreated")
+	gateway.Register[socketevent.ListItemUpdated](eventRegistry, "ListItemUpdated")
+	gateway.Register[socketevent.ListItemDeleted](eventRegistry, "ListItemDeleted")
+	gateway.Register[socketevent.ListItemCompleted](eventRegistry, "ListItemCompleted")
+	gateway.Register[socketevent.ListItemUncompleted](eventRegistry, "ListItemUncompleted")
+	gateway.Register[socketevent.DocCommentCreated](eventRegistry, "DocCommentCreated")
+	gateway.Register[socketevent.DocCommentUpdated](eventRegistry, "DocCommentUpdated")
+	gateway.Register[socketevent.DocCommentDeleted](eventRegistry, "DocCommentDeleted")
+	gateway.Register[socketevent.AnnouncementCreated](eventRegistry, "AnnouncementCreated")
+	gateway.Register[socketevent.AnnouncementUpdated](eventRegistry, "AnnouncementUpdated")
+	gateway.Register[socketevent.AnnouncementDeleted](eventRegistry, "AnnouncementDeleted")
+	gateway.Register[socketevent.AnnouncementCommentCreated](eventRegistry, "AnnouncementCommentCreated")
+	gateway.Register[socketevent.AnnouncementCommentUpdated](eventRegistry, "AnnouncementCommentUpdated")
+	gateway.Register[socketevent.AnnouncementCommentDeleted](eventRegistry, "AnnouncementCommentDeleted")
+	gateway.Register[socketevent.CalendarEventCommentCreated](eventRegistry, "CalendarEventCommentCreated")
+	gateway.Register[socketevent.CalendarEventCommentUpdated](eventRegistry, "CalendarEventCommentUpdated")
+	gateway.Register[socketevent.CalendarEventCommentDeleted](eventRegistry, "CalendarEventCommentDeleted")
+	gateway.Register[socketevent.ChannelMessageReactionCreated](eventRegistry, "ChannelMessageReactionCreated")
+	gateway.Register[socketevent.ChannelMessageReactionDeleted](eventRegistry, "ChannelMessageReactionDeleted")
+	gateway.Register[socketevent.ChannelMessageReactionManyDeleted](eventRegistry, "ChannelMessageReactionManyDeleted")
+	gateway.Register[socketevent.ForumTopicReactionCreated](eventRegistry, "ForumTopicReactionCreated")
+	gateway.Register[socketevent.ForumTopicReactionDeleted](eventRegistry, "ForumTopicReactionDeleted")
+	gateway.Register[socketevent.ForumTopicCommentReactionCreated](eventRegistry, "ForumTopicCommentReactionCreated")
+	gateway.Register[socketevent.ForumTopicCommentReactionDeleted](eventRegistry, "ForumTopicCommentReactionDeleted")
+	gateway.Register[socketevent.DocReactionCreated](eventRegistry, "DocReactionCreated")
+	gateway.Register[socketevent.DocReactionDeleted](eventRegistry, "DocReactionDeleted")
+	gateway.Register[socketevent.DocCommentReactionCreated](eventRegistry, "DocCommentReactionCreated")
+	gateway.Register[socketevent.DocCommentReactionDeleted](eventRegistry, "DocCommentReactionDeleted")
+	gateway.Register[socketevent.AnnouncementReactionCreated](eventRegistry, "AnnouncementReactionCreated")
+	gateway.Register[socketevent.AnnouncementReactionDeleted](eventRegistry, "AnnouncementReactionDeleted")
+	gateway.Register[socketevent.AnnouncementCommentReactionCreated](eventRegistry, "AnnouncementCommentReactionCreated")
+	gateway.Register[socketevent.AnnouncementCommentReactionDeleted](eventRegistry, "AnnouncementCommentReactionDeleted")
+	gateway.Register[socketevent.CalendarEventReactionCreated](eventRegistry, "CalendarEventReactionCreated")
+	gateway.Register[socketevent.CalendarEventReactionDeleted](eventRegistry, "CalendarEventReactionDeleted")
+	gateway.Register[socketevent.CalendarEventCommentReactionCreated](eventRegistry, "CalendarEventCommentReactionCreated")
+	gateway.Register[socketevent.CalendarEventCommentReactionDeleted](eventRegistry, "CalendarEventCommentReactionDeleted")
+	gateway.Register[socketevent.CalendarEventSeriesUpdated](eventRegistry, "CalendarEventSeriesUpdated")
+	gateway.Register[socketevent.CalendarEventSeriesDeleted](eventRegistry, "CalendarEventSeriesDeleted")
+	gateway.Register[socketevent.UserStatusCreated](eventRegistry, "UserStatusCreated")
+	gateway.Register[socketevent.UserStatusDeleted](eventRegistry, "UserStatusDeleted")
+
+	// Emitted by the client itself to report the connection state
+	gateway.Register[socketevent.Connected](eventRegistry, "Connected")
+	gateway.Register[socketevent.Disconnected](eventRegistry, "Disconnected")
+	gateway.Register[socketevent.Reconnecting](eventRegistry, "Reconnecting")
+	gateway.Register[socketevent.Resumed](eventRegistry, "Resumed")
+	gateway.Register[socketevent.ResyncRequired](eventRegistry, "ResyncRequired")
 }
 
+// RawEvent is a message as it was received from the gateway, see Client.OnRaw
 type RawEvent struct {
-	OP   int             `json:"op"`
-	T    string          `json:"t"`
-	S    string          `json:"s"`
+	// The op code, 0 for events
+	OP int `json:"op"`
+
+	// The event name, e.g. ChatMessageCreated
+	T string `json:"t"`
+
+	// The message ID, used to resume the session
+	S string `json:"s"`
+
+	// The event data
 	Data json.RawMessage `json:"d"`
 }
 
@@ -48,156 +135,191 @@ type WelcomeOP struct {
 	HeartbeatInterval int `json:"heartbeatIntervalMs"`
 }
 
-var (
-	newline = []byte{'\n'}
-	space   = []byte{' '}
-)
-
-func (r *Client) Open() {
-	var err error
+// Open connects to the Guilded gateway and starts listening for events.
+// Dropped connections are re-established with exponential backoff, see event.Connected,
+// event.Disconnected and event.Reconnecting to follow the connection state.
+// An error is only returned if the first connection attempt fails.
+func (r *Client) Open() error {
+	r = r.base()
 
 	r.Lock()
 	defer r.Unlock()
 
-	if r.conn != nil {
-		return
+	if r.session == nil {
+		r.session = gateway.New(gateway.Config{
+			URL:              r.websocketURL,
+			Dialer:           r.dialer,
+			LastMessageID:    r.lastMessageID,
+			MissedHeartbeats: r.missedHeartbeats,
+			Header: func() http.Header {
+				header := http.Header{}
+				header.Add("Authorization", fmt.Sprintf("Bearer %s", r.Token))
+				header.Add("User-Agent", r.userAgent)
+				return header
+			},
+			OnMessage: r.onEvent,
+			Logger:    r.logger,
+			OnConnect: func() {
+				r.logger.Debug("Connected to websocket")
+				r.post("Connected", &socketevent.Connected{})
+			},
+			OnDisconnect: func(err error) {
+				r.logger.Warn("Lost websocket connection", "error", err)
+				r.post("Disconnected", &socketevent.Disconnected{Err: err})
+			},
+			OnReconnect: func(attempt int, delay time.Duration) {
+				r.post("Reconnecting", &socketevent.Reconnecting{Attempt: attempt, Delay: delay})
+			},
+			OnResume: func(lastMessageID string) {
+				r.post("Resumed", &socketevent.Resumed{LastMessageID: lastMessageID})
+			},
+			OnResync: func(op int) {
+				r.post("ResyncRequired", &socketevent.ResyncRequired{Op: op})
+			},
+		})
 	}
 
-	header := http.Header{}
-	header.Add("Authorization", fmt.Sprintf("Bearer %s", r.Token))
+	r.pool.Start()
 
-	r.conn, _, err = websocket.DefaultDialer.Dial("wss://www.guilded.gg/websocket/v1", header)
+	err := r.session.Open()
 	if err != nil {
-		log.Fatalln("Failed to connect to websocket: ", err.Error())
+		r.pool.Stop(context.Background())
 	}
 
-	r.conn.SetCloseHandler(func(code int, text string) error {
-		return nil
-	})
+	return err
+}
 
-	defer func() {
-		if err != nil {
-			r.conn.Close()
-			r.conn = nil
-		}
-	}()
+// LastMessageID returns the ID of the last event received from the gateway.
+// Store it and pass it as Config.LastMessageID to resume after a restart.
+func (r *Client) LastMessageID() string {
+	r = r.base()
 
-	_, m, err := r.conn.ReadMessage()
-	if err != nil {
-		log.Fatalln("Failed to read message: ", err.Error())
-	}
-	m = bytes.TrimSpace(bytes.Replace(m, newline, space, -1))
+	r.RLock()
+	defer r.RUnlock()
 
-	event := r.onWelcomeMessage(m)
-	if event == nil {
-		return
+	if r.session == nil {
+		return r.lastMessageID
 	}
 
-	r.listening = make(chan struct{})
-
-	go r.heartbeat(r.conn, r.listening, event.HeartbeatInterval)
-	go r.listen(r.conn, r.listening)
-
-	log.Println("Listening for messages in main")
+	return r.session.LastMessageID()
 }
 
-func (r *Client) listen(wsConn *websocket.Conn, listening <-chan struct{}) {
-	for {
-		select {
-		case <-listening:
-			return
-		default:
-			_, msg, err := wsConn.ReadMessage()
-			if err != nil {
-				log.Println("Failed to read message: ", err.Error())
-				return
-			}
-
-			msg = bytes.TrimSpace(bytes.Replace(msg, newline, space, -1))
-
-			r.onEvent(msg)
-		}
-	}
-}
+// Stats is a snapshot of the gateway connection health, see Client.Stats
+type Stats = gateway.SessionStats
 
-func (r *Client) heartbeat(wsConn *websocket.Conn, listening <-chan struct{}, intervalMs int) {
-	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			log.Println("Sending heartbeat")
-			err := wsConn.WriteMessage(websocket.TextMessage, []byte(""))
-			if err != nil {
-				log.Println("Failed to send heartbeat: ", err.Error())
-			}
-		case <-listening:
-			return
-		}
-	}
-}
+// Stats reports the heartbeat latency, how long the connection has been up, how often it reconnected,
+// and how many events of each type were received since Open
+func (r *Client) Stats() Stats {
+	r = r.base()
 
-func (r *Client) Close() {
-	r.Lock()
+	r.RLock()
+	defer r.RUnlock()
 
-	if r.listening != nil {
-		log.Println("Closing listening channel")
-		close(r.listening)
-		r.listening = nil
+	if r.session == nil {
+		return Stats{}
 	}
 
-	if r.conn != nil {
-		log.Println("Closing websocket connection")
+	return r.session.Stats()
+}
 
-		r.wsMutex.Lock()
-		err := r.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		r.wsMutex.Unlock()
-		if err != nil {
-			log.Println("Failed to write close message: ", err.Error())
-		}
+// Close sends a close frame to Guilded, closes the connection and waits for the handlers
+// of events that were already received, for up to Config.ShutdownTimeout
+func (r *Client) Close() {
+	r = r.base()
 
-		time.Sleep(1 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
 
-		log.Println("Closing websocket connection")
+	err := r.shutdown(ctx)
+	if err != nil {
+		r.logger.Warn("Failed to shut down", "error", err)
+	}
+}
 
-		err = r.conn.Close()
-		if err != nil {
-			log.Println("Failed to close websocket connection: ", err.Error())
-		}
+func (r *Client) shutdown(ctx context.Context) error {
+	// Don't hold the lock while waiting, Stats and LastMessageID need it
+	r.RLock()
+	session := r.session
+	r.RUnlock()
 
-		r.conn = nil
+	if session == nil {
+		return nil
 	}
 
-	r.Unlock()
+	err := session.Shutdown(ctx)
+	if err != nil {
+		r.logger.Warn("Failed to close websocket connection", "error", err)
+	}
 
-	log.Println("Closed websocket connection")
+	// Let the handlers finish the events that were already received
+	err = r.pool.Stop(ctx)
+	if err != nil {
+		return fmt.Errorf("client: event handlers did not finish: %w", err)
+	}
 
-	return
+	r.logger.Debug("Closed websocket connection")
+	return nil
 }
 
-func (r *Client) onWelcomeMessage(msg []byte) *WelcomeOP {
-	var err error
-	reader := bytes.NewBuffer(msg)
+var (
+	// ErrInterrupted is returned by Run when the process received SIGINT or SIGTERM
+	ErrInterrupted = errors.New("client: interrupted")
+
+	// ErrUnauthorized is returned by Run when Guilded rejected the token while reconnecting
+	ErrUnauthorized = gateway.ErrUnauthorized
 
-	var re RawEvent
-	decoder := json.NewDecoder(reader)
+	// ErrClosed is returned by Run when the connection was closed elsewhere, e.g. by calling Close
+	ErrClosed = gateway.ErrClosed
+)
 
-	err = decoder.Decode(&re)
+// Run opens the connection and blocks until ctx is done, the process receives SIGINT or SIGTERM,
+// or the session ends because Guilded rejected the token or it was closed. It then closes the connection
+// like Close and returns why it stopped: the context's error, ErrInterrupted, ErrUnauthorized or ErrClosed.
+// If the event handlers don't finish within Config.ShutdownTimeout, that is reported along with it.
+//
+//	err := c.Run(ctx)
+//	if err != nil && !errors.Is(err, client.ErrInterrupted) {
+//		log.Fatal(err)
+//	}
+func (r *Client) Run(ctx context.Context) error {
+	r = r.base()
+
+	err := r.Open()
 	if err != nil {
-		log.Println("Failed to decode raw event")
+		return err
 	}
 
-	if re.OP != 1 {
-		log.Println("Expected OP code 1, got", re.OP)
-		return nil
+	r.Lock()
+	if r.interrupt == nil {
+		r.interrupt = make(chan os.Signal, 1)
 	}
+	interrupt := r.interrupt
+	done := r.session.Done()
+	r.Unlock()
+
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	var reason error
+	select {
+	case <-ctx.Done():
+		reason = context.Cause(ctx)
+	case sig := <-interrupt:
+		reason = fmt.Errorf("%w (%s)", ErrInterrupted, sig)
+	case <-done:
+		reason = r.session.Err()
+		if reason == nil {
+			reason = ErrClosed
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
 
-	var h WelcomeOP
-	err = json.Unmarshal(re.Data, &h)
+	err = r.shutdown(shutdownCtx)
 	if err != nil {
-		log.Printf("Failed to unmarshal event data for %q. Error: %s", re.T, err.Error())
+		return errors.Join(reason, err)
 	}
 
-	return &h
+	return reason
 }
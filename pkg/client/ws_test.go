@@ -0,0 +1,151 @@
+package client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/itschip/guildedgo/pkg/client"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loadFixtures reads the gateway messages in the root testdata/events, one per event type
+func loadFixtures(t *testing.T) map[string]client.RawEvent {
+	files, err := filepath.Glob("../../testdata/events/*.json")
+	if err != nil || len(files) == 0 {
+		t.Error("no fixtures found", err)
+		t.FailNow()
+	}
+
+	fixtures := make(map[string]client.RawEvent)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		var re client.RawEvent
+		err = json.Unmarshal(data, &re)
+		if err != nil {
+			t.Errorf("%s: %s", file, err)
+			t.FailNow()
+		}
+
+		fixtures[re.T] = re
+	}
+
+	return fixtures
+}
+
+// diff lists the values of want that are missing from got, or differ
+func diff(path string, want, got any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %v", path, got)}
+		}
+
+		var diffs []string
+		for k, v := range w {
+			diffs = append(diffs, diff(path+"."+k, v, g[k])...)
+		}
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return []string{fmt.Sprintf("%s: expected %v, got %v", path, want, got)}
+		}
+
+		var diffs []string
+		for i := range w {
+			diffs = append(diffs, diff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return diffs
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return []string{fmt.Sprintf("%s: expected %v, got %v", path, want, got)}
+		}
+		return nil
+	}
+}
+
+func TestEventFixtures(t *testing.T) {
+	fixtures := loadFixtures(t)
+
+	var messages []string
+	for _, re := range fixtures {
+		msg, _ := json.Marshal(re)
+		messages = append(messages, string(msg))
+	}
+
+	srv := fakeGateway(t, messages...)
+	defer srv.Close()
+
+	c := client.New(client.Config{
+		Token:        "token",
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	var mu sync.Mutex
+	received := make(map[string]any)
+	done := make(chan struct{})
+
+	c.OnAny(func(_ *client.Client, event string, v any) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if _, ok := fixtures[event]; !ok {
+			return
+		}
+
+		received[event] = v
+		if len(received) == len(fixtures) {
+			close(done)
+		}
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for events")
+		t.FailNow()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for name, re := range fixtures {
+		v := received[name]
+		if _, ok := v.(*client.RawEvent); ok {
+			t.Errorf("%s: no type is registered for the event", name)
+			continue
+		}
+
+		if reflect.TypeOf(v).Elem().Name() != name {
+			t.Errorf("%s: decoded into %T", name, v)
+		}
+
+		// Every field of the fixture has to survive decoding and encoding the event again
+		var want, got any
+		json.Unmarshal(re.Data, &want)
+		data, _ := json.Marshal(v)
+		json.Unmarshal(data, &got)
+
+		for _, d := range diff(name, want, got) {
+			t.Error(d)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package doc
+
+import "github.com/itschip/guildedgo/pkg/channel"
+
+type Doc struct {
+	ID        int             `json:"id"`
+	ServerID  string          `json:"serverId"`
+	ChannelID string          `json:"channelId"`
+	Title     string          `json:"title"`
+	Content   string          `json:"content"`
+	Mentions  channel.Mention `json:"mentions,omitempty"`
+	CreatedAt string          `json:"createdAt"`
+	CreatedBy string          `json:"createdBy"`
+	UpdatedAt string          `json:"updatedAt,omitempty"`
+	UpdatedBy string          `json:"updatedBy,omitempty"`
+}
+
+type Comment struct {
+	ID        int             `json:"id"`
+	Content   string          `json:"content"`
+	CreatedAt string          `json:"createdAt"`
+	CreatedBy string          `json:"createdBy"`
+	UpdatedAt string          `json:"updatedAt,omitempty"`
+	ChannelID string          `json:"channelId"`
+	DocID     int             `json:"docId"`
+	Mentions  channel.Mention `json:"mentions,omitempty"`
+}
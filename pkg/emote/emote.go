@@ -0,0 +1,7 @@
+package emote
+
+type Emote struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
@@ -1,12 +1,23 @@
 package event
 
 import (
+	"time"
+
 	"github.com/itschip/guildedgo/pkg/ban"
+	"github.com/itschip/guildedgo/pkg/calendar"
+	"github.com/itschip/guildedgo/pkg/category"
 	"github.com/itschip/guildedgo/pkg/channel"
+	"github.com/itschip/guildedgo/pkg/doc"
+	"github.com/itschip/guildedgo/pkg/emote"
+	"github.com/itschip/guildedgo/pkg/forum"
+	"github.com/itschip/guildedgo/pkg/group"
+	"github.com/itschip/guildedgo/pkg/list"
 	"github.com/itschip/guildedgo/pkg/member"
 	"github.com/itschip/guildedgo/pkg/message"
+	"github.com/itschip/guildedgo/pkg/role"
 	"github.com/itschip/guildedgo/pkg/server"
 	"github.com/itschip/guildedgo/pkg/social"
+	"github.com/itschip/guildedgo/pkg/user"
 	"github.com/itschip/guildedgo/pkg/webhook"
 )
 
@@ -89,8 +100,8 @@ type ServerMemberUpdated struct {
 type ServerRolesUpdated struct {
 	ServerID      string `json:"serverId"`
 	MemberRoleIds []struct {
-		UserID  string   `json:"userId"`
-		RoleIds []string `json:"roleIds"`
+		UserID  string `json:"userId"`
+		RoleIds []int  `json:"roleIds"`
 	} `json:"memberRoleIds"`
 }
 
@@ -133,6 +144,7 @@ type ServerWebhookUpdated struct {
 	ServerID string          `json:"serverId"`
 	Webhook  webhook.Webhook `json:"webhook"`
 }
+
 type ChannelArchived struct {
 	ServerID string                `json:"serverId"`
 	Channel  channel.ServerChannel `json:"channel"`
@@ -142,3 +154,472 @@ type ChannelRestored struct {
 	ServerID string                `json:"serverId"`
 	Channel  channel.ServerChannel `json:"channel"`
 }
+
+type DocCreated struct {
+	ServerID string  `json:"serverId"`
+	Doc      doc.Doc `json:"doc"`
+}
+
+type DocUpdated struct {
+	ServerID string  `json:"serverId"`
+	Doc      doc.Doc `json:"doc"`
+}
+
+type DocDeleted struct {
+	ServerID string  `json:"serverId"`
+	Doc      doc.Doc `json:"doc"`
+}
+
+type CalendarEventCreated struct {
+	ServerID      string                 `json:"serverId"`
+	CalendarEvent calendar.CalendarEvent `json:"calendarEvent"`
+}
+
+type CalendarEventUpdated struct {
+	ServerID      string                 `json:"serverId"`
+	CalendarEvent calendar.CalendarEvent `json:"calendarEvent"`
+}
+
+type CalendarEventDeleted struct {
+	ServerID      string                 `json:"serverId"`
+	CalendarEvent calendar.CalendarEvent `json:"calendarEvent"`
+}
+
+type CalendarEventRsvpUpdated struct {
+	ServerID          string                     `json:"serverId"`
+	CalendarEventRsvp calendar.CalendarEventRsvp `json:"calendarEventRsvp"`
+}
+
+type CalendarEventRsvpManyUpdated struct {
+	ServerID           string                       `json:"serverId"`
+	CalendarEventRsvps []calendar.CalendarEventRsvp `json:"calendarEventRsvps"`
+}
+
+type CalendarEventRsvpDeleted struct {
+	ServerID          string                     `json:"serverId"`
+	CalendarEventRsvp calendar.CalendarEventRsvp `json:"calendarEventRsvp"`
+}
+
+type ForumTopicCreated struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicUpdated struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicDeleted struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicPinned struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicUnpinned struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicLocked struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicUnlocked struct {
+	ServerID   string      `json:"serverId"`
+	ForumTopic forum.Topic `json:"forumTopic"`
+}
+
+type ForumTopicCommentCreated struct {
+	ServerID          string        `json:"serverId"`
+	ForumTopicComment forum.Comment `json:"forumTopicComment"`
+}
+
+type ForumTopicCommentUpdated struct {
+	ServerID          string        `json:"serverId"`
+	ForumTopicComment forum.Comment `json:"forumTopicComment"`
+}
+
+type ForumTopicCommentDeleted struct {
+	ServerID          string        `json:"serverId"`
+	ForumTopicComment forum.Comment `json:"forumTopicComment"`
+}
+
+type RoleCreated struct {
+	ServerID string    `json:"serverId"`
+	Role     role.Role `json:"role"`
+}
+
+type RoleUpdated struct {
+	ServerID string    `json:"serverId"`
+	Role     role.Role `json:"role"`
+}
+
+type RoleDeleted struct {
+	ServerID string    `json:"serverId"`
+	Role     role.Role `json:"role"`
+}
+
+type GroupCreated struct {
+	ServerID string      `json:"serverId"`
+	Group    group.Group `json:"group"`
+}
+
+type GroupUpdated struct {
+	ServerID string      `json:"serverId"`
+	Group    group.Group `json:"group"`
+}
+
+type GroupDeleted struct {
+	ServerID string      `json:"serverId"`
+	Group    group.Group `json:"group"`
+}
+
+type CategoryCreated struct {
+	ServerID string            `json:"serverId"`
+	Category category.Category `json:"category"`
+}
+
+type CategoryUpdated struct {
+	ServerID string            `json:"serverId"`
+	Category category.Category `json:"category"`
+}
+
+type CategoryDeleted struct {
+	ServerID string            `json:"serverId"`
+	Category category.Category `json:"category"`
+}
+
+type ListItemCreated struct {
+	ServerID string    `json:"serverId"`
+	ListItem list.Item `json:"listItem"`
+}
+
+type ListItemUpdated struct {
+	ServerID string    `json:"serverId"`
+	ListItem list.Item `json:"listItem"`
+}
+
+type ListItemDeleted struct {
+	ServerID string    `json:"serverId"`
+	ListItem list.Item `json:"listItem"`
+}
+
+type ListItemCompleted struct {
+	ServerID string    `json:"serverId"`
+	ListItem list.Item `json:"listItem"`
+}
+
+type ListItemUncompleted struct {
+	ServerID string    `json:"serverId"`
+	ListItem list.Item `json:"listItem"`
+}
+
+type DocCommentCreated struct {
+	ServerID   string      `json:"serverId"`
+	DocComment doc.Comment `json:"docComment"`
+}
+
+type DocCommentUpdated struct {
+	ServerID   string      `json:"serverId"`
+	DocComment doc.Comment `json:"docComment"`
+}
+
+type DocCommentDeleted struct {
+	ServerID   string      `json:"serverId"`
+	DocComment doc.Comment `json:"docComment"`
+}
+
+type AnnouncementCreated struct {
+	ServerID     string               `json:"serverId"`
+	Announcement channel.Announcement `json:"announcement"`
+}
+
+type AnnouncementUpdated struct {
+	ServerID     string               `json:"serverId"`
+	Announcement channel.Announcement `json:"announcement"`
+}
+
+type AnnouncementDeleted struct {
+	ServerID     string               `json:"serverId"`
+	Announcement channel.Announcement `json:"announcement"`
+}
+
+type AnnouncementCommentCreated struct {
+	ServerID            string          `json:"serverId"`
+	AnnouncementComment channel.Comment `json:"announcementComment"`
+}
+
+type AnnouncementCommentUpdated struct {
+	ServerID            string          `json:"serverId"`
+	AnnouncementComment channel.Comment `json:"announcementComment"`
+}
+
+type AnnouncementCommentDeleted struct {
+	ServerID            string          `json:"serverId"`
+	AnnouncementComment channel.Comment `json:"announcementComment"`
+}
+
+type CalendarEventCommentCreated struct {
+	ServerID             string           `json:"serverId"`
+	CalendarEventComment calendar.Comment `json:"calendarEventComment"`
+}
+
+type CalendarEventCommentUpdated struct {
+	ServerID             string           `json:"serverId"`
+	CalendarEventComment calendar.Comment `json:"calendarEventComment"`
+}
+
+type CalendarEventCommentDeleted struct {
+	ServerID             string           `json:"serverId"`
+	CalendarEventComment calendar.Comment `json:"calendarEventComment"`
+}
+
+// ChannelMessageReaction is a reaction to a chat message
+type ChannelMessageReaction struct {
+	ChannelID string      `json:"channelId"`
+	CreatedBy string      `json:"createdBy"`
+	Emote     emote.Emote `json:"emote"`
+	MessageID string      `json:"messageId"`
+}
+
+type ChannelMessageReactionCreated struct {
+	ServerID string                 `json:"serverId,omitempty"`
+	Reaction ChannelMessageReaction `json:"reaction"`
+}
+
+type ChannelMessageReactionDeleted struct {
+	ServerID string `json:"serverId,omitempty"`
+
+	// The ID of the user who deleted this reaction
+	DeletedBy string                 `json:"deletedBy"`
+	Reaction  ChannelMessageReaction `json:"reaction"`
+}
+
+// ChannelMessageReactionManyDeleted is sent when the reactions of a message are removed in bulk
+type ChannelMessageReactionManyDeleted struct {
+	ServerID  string `json:"serverId,omitempty"`
+	ChannelID string `json:"channelId"`
+	MessageID string `json:"messageId"`
+
+	// The ID of the user who deleted the reactions
+	DeletedBy string `json:"deletedBy"`
+
+	// The number of reactions that were removed
+	Count int `json:"count"`
+
+	// The emote whose reactions were removed, nil if all reactions were removed
+	Emote *emote.Emote `json:"emote,omitempty"`
+}
+
+// ForumTopicReaction is a reaction to a forum topic
+type ForumTopicReaction struct {
+	ChannelID    string      `json:"channelId"`
+	CreatedBy    string      `json:"createdBy"`
+	Emote        emote.Emote `json:"emote"`
+	ForumTopicID int         `json:"forumTopicId"`
+}
+
+type ForumTopicReactionCreated struct {
+	ServerID string             `json:"serverId"`
+	Reaction ForumTopicReaction `json:"reaction"`
+}
+
+type ForumTopicReactionDeleted struct {
+	ServerID string             `json:"serverId"`
+	Reaction ForumTopicReaction `json:"reaction"`
+}
+
+// ForumTopicCommentReaction is a reaction to a comment on a forum topic
+type ForumTopicCommentReaction struct {
+	ChannelID           string      `json:"channelId"`
+	CreatedBy           string      `json:"createdBy"`
+	Emote               emote.Emote `json:"emote"`
+	ForumTopicID        int         `json:"forumTopicId"`
+	ForumTopicCommentID int         `json:"forumTopicCommentId"`
+}
+
+type ForumTopicCommentReactionCreated struct {
+	ServerID string                    `json:"serverId"`
+	Reaction ForumTopicCommentReaction `json:"reaction"`
+}
+
+type ForumTopicCommentReactionDeleted struct {
+	ServerID string                    `json:"serverId"`
+	Reaction ForumTopicCommentReaction `json:"reaction"`
+}
+
+// DocReaction is a reaction to a doc
+type DocReaction struct {
+	ChannelID string      `json:"channelId"`
+	CreatedBy string      `json:"createdBy"`
+	Emote     emote.Emote `json:"emote"`
+	DocID     int         `json:"docId"`
+}
+
+type DocReactionCreated struct {
+	ServerID string      `json:"serverId"`
+	Reaction DocReaction `json:"reaction"`
+}
+
+type DocReactionDeleted struct {
+	ServerID string      `json:"serverId"`
+	Reaction DocReaction `json:"reaction"`
+}
+
+// DocCommentReaction is a reaction to a comment on a doc
+type DocCommentReaction struct {
+	ChannelID    string      `json:"channelId"`
+	CreatedBy    string      `json:"createdBy"`
+	Emote        emote.Emote `json:"emote"`
+	DocID        int         `json:"docId"`
+	DocCommentID int         `json:"docCommentId"`
+}
+
+type DocCommentReactionCreated struct {
+	ServerID string             `json:"serverId"`
+	Reaction DocCommentReaction `json:"reaction"`
+}
+
+type DocCommentReactionDeleted struct {
+	ServerID string             `json:"serverId"`
+	Reaction DocCommentReaction `json:"reaction"`
+}
+
+// AnnouncementReaction is a reaction to an announcement
+type AnnouncementReaction struct {
+	ChannelID      string      `json:"channelId"`
+	CreatedBy      string      `json:"createdBy"`
+	Emote          emote.Emote `json:"emote"`
+	AnnouncementID string      `json:"announcementId"`
+}
+
+type AnnouncementReactionCreated struct {
+	ServerID string               `json:"serverId"`
+	Reaction AnnouncementReaction `json:"reaction"`
+}
+
+type AnnouncementReactionDeleted struct {
+	ServerID string               `json:"serverId"`
+	Reaction AnnouncementReaction `json:"reaction"`
+}
+
+// AnnouncementCommentReaction is a reaction to a comment on an announcement
+type AnnouncementCommentReaction struct {
+	ChannelID             string      `json:"channelId"`
+	CreatedBy             string      `json:"createdBy"`
+	Emote                 emote.Emote `json:"emote"`
+	AnnouncementID        string      `json:"announcementId"`
+	AnnouncementCommentID int         `json:"announcementCommentId"`
+}
+
+type AnnouncementCommentReactionCreated struct {
+	ServerID string                      `json:"serverId"`
+	Reaction AnnouncementCommentReaction `json:"reaction"`
+}
+
+type AnnouncementCommentReactionDeleted struct {
+	ServerID string                      `json:"serverId"`
+	Reaction AnnouncementCommentReaction `json:"reaction"`
+}
+
+// CalendarEventReaction is a reaction to a calendar event
+type CalendarEventReaction struct {
+	ChannelID       string      `json:"channelId"`
+	CreatedBy       string      `json:"createdBy"`
+	Emote           emote.Emote `json:"emote"`
+	CalendarEventID int         `json:"calendarEventId"`
+}
+
+type CalendarEventReactionCreated struct {
+	ServerID string                `json:"serverId"`
+	Reaction CalendarEventReaction `json:"reaction"`
+}
+
+type CalendarEventReactionDeleted struct {
+	ServerID string                `json:"serverId"`
+	Reaction CalendarEventReaction `json:"reaction"`
+}
+
+// CalendarEventCommentReaction is a reaction to a comment on a calendar event
+type CalendarEventCommentReaction struct {
+	ChannelID              string      `json:"channelId"`
+	CreatedBy              string      `json:"createdBy"`
+	Emote                  emote.Emote `json:"emote"`
+	CalendarEventID        int         `json:"calendarEventId"`
+	CalendarEventCommentID int         `json:"calendarEventCommentId"`
+}
+
+type CalendarEventCommentReactionCreated struct {
+	ServerID string                       `json:"serverId"`
+	Reaction CalendarEventCommentReaction `json:"reaction"`
+}
+
+type CalendarEventCommentReactionDeleted struct {
+	ServerID string                       `json:"serverId"`
+	Reaction CalendarEventCommentReaction `json:"reaction"`
+}
+
+type CalendarEventSeriesUpdated struct {
+	ServerID            string          `json:"serverId"`
+	CalendarEventSeries calendar.Series `json:"calendarEventSeries"`
+
+	// The calendar event the series was updated from, if any
+	CalendarEventID int `json:"calendarEventId,omitempty"`
+}
+
+type CalendarEventSeriesDeleted struct {
+	ServerID            string          `json:"serverId"`
+	CalendarEventSeries calendar.Series `json:"calendarEventSeries"`
+
+	// The calendar event the series was deleted from, if any
+	CalendarEventID int `json:"calendarEventId,omitempty"`
+}
+
+type UserStatusCreated struct {
+	UserID     string      `json:"userId"`
+	UserStatus user.Status `json:"userStatus"`
+
+	// The ISO 8601 timestamp that the status expires at, if any
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+type UserStatusDeleted struct {
+	UserID     string      `json:"userId"`
+	UserStatus user.Status `json:"userStatus"`
+}
+
+// Connected is emitted whenever the gateway connection is established, including reconnects
+type Connected struct{}
+
+// Disconnected is emitted when the gateway connection is lost. The client reconnects on its own
+type Disconnected struct {
+	// Why the connection was lost
+	Err error
+}
+
+// Reconnecting is emitted before every reconnect attempt
+type Reconnecting struct {
+	// The number of the attempt, starting at 1
+	Attempt int
+
+	// How long the client waits before the attempt
+	Delay time.Duration
+}
+
+// Resumed is emitted once Guilded replayed the events missed while the client was disconnected
+type Resumed struct {
+	LastMessageID string
+}
+
+// ResyncRequired is emitted when Guilded can't replay the events missed while the client was disconnected.
+// The client reconnects without resuming, so any state kept from events should be fetched again.
+type ResyncRequired struct {
+	// The op code Guilded sent, 8 for an invalid last message ID or 9 for an internal error
+	Op int
+}
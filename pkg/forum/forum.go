@@ -0,0 +1,34 @@
+package forum
+
+import "github.com/itschip/guildedgo/pkg/channel"
+
+type Topic struct {
+	ID        int    `json:"id"`
+	ServerID  string `json:"serverId"`
+	ChannelID string `json:"channelId"`
+
+	// The title of the forum topic (min length 1; max length 500)
+	Title              string `json:"title"`
+	CreatedAt          string `json:"createdAt"`
+	CreatedBy          string `json:"createdBy"`
+	CreatedByWebhookID string `json:"createdByWebhookId,omitempty"`
+	UpdatedAt          string `json:"updatedAt,omitempty"`
+
+	// Updated whenever there is any activity on the posts within the forum topic
+	BumpedAt string          `json:"bumpedAt,omitempty"`
+	IsPinned bool            `json:"isPinned,omitempty"`
+	IsLocked bool            `json:"isLocked,omitempty"`
+	Content  string          `json:"content"`
+	Mentions channel.Mention `json:"mentions,omitempty"`
+}
+
+type Comment struct {
+	ID           int             `json:"id"`
+	Content      string          `json:"content"`
+	CreatedAt    string          `json:"createdAt"`
+	UpdatedAt    string          `json:"updatedAt,omitempty"`
+	ChannelID    string          `json:"channelId"`
+	ForumTopicID int             `json:"forumTopicId"`
+	CreatedBy    string          `json:"createdBy"`
+	Mentions     channel.Mention `json:"mentions,omitempty"`
+}
@@ -15,7 +15,6 @@ const (
 	guildedApi = "https://www.guilded.gg/api/v1"
 )
 
-// TODO: Add to socket interfaces
 type Group struct {
 	ID          string `json:"id"`
 	ServerID    string `json:"serverId"`
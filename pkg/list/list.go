@@ -0,0 +1,29 @@
+package list
+
+import "github.com/itschip/guildedgo/pkg/channel"
+
+type Item struct {
+	ID                 string          `json:"id"`
+	ServerID           string          `json:"serverId"`
+	ChannelID          string          `json:"channelId"`
+	Message            string          `json:"message"`
+	Mentions           channel.Mention `json:"mentions,omitempty"`
+	CreatedAt          string          `json:"createdAt"`
+	CreatedBy          string          `json:"createdBy"`
+	CreatedByWebhookID string          `json:"createdByWebhookId,omitempty"`
+	UpdatedAt          string          `json:"updatedAt,omitempty"`
+	UpdatedBy          string          `json:"updatedBy,omitempty"`
+	ParentListItemID   string          `json:"parentListItemId,omitempty"`
+	CompletedAt        string          `json:"completedAt,omitempty"`
+	CompletedBy        string          `json:"completedBy,omitempty"`
+	Note               *Note           `json:"note,omitempty"`
+}
+
+type Note struct {
+	CreatedAt string          `json:"createdAt"`
+	CreatedBy string          `json:"createdBy"`
+	UpdatedAt string          `json:"updatedAt,omitempty"`
+	UpdatedBy string          `json:"updatedBy,omitempty"`
+	Mentions  channel.Mention `json:"mentions,omitempty"`
+	Content   string          `json:"content"`
+}
@@ -3,6 +3,8 @@ package message
 import (
 	"io"
 	"net/http"
+
+	"github.com/itschip/guildedgo/pkg/channel"
 )
 
 type MessageClient interface {
@@ -46,8 +48,7 @@ type ChatMessage struct {
 	// If set, this message did not notify mention or reply recipients (default false)
 	IsSilent bool `json:"isSilent,omitempty"`
 
-	// FIXME: add this back
-	// Mentions `json:"mentions,omitempty"`
+	Mentions channel.Mention `json:"mentions,omitempty"`
 
 	// The ISO 8601 timestamp that the message was created at.
 	CreatedAt string `json:"createdAt"`
@@ -75,7 +76,7 @@ const (
 // bold, strikethrough, underline, inline code, block code, reaction, and mention.
 type ChatEmbed struct {
 	// Main header of the embed (max length 256)
-	Title string `json:"embed,omitempty"`
+	Title string `json:"title,omitempty"`
 
 	// Subtext of the embed (max length 2048)
 	Description string `json:"description,omitempty"`
@@ -125,7 +126,7 @@ type ChatEmbedImage struct {
 
 type ChatEmbedAuthor struct {
 	// Name of the author (max length 256)
-	Name string `jsojn:"name,omitempty"`
+	Name string `json:"name,omitempty"`
 
 	// URL to linkify the author's name field (max length 1024; regex ^(?!attachment))
 	URL string `json:"url,omitempty"`
@@ -0,0 +1,34 @@
+package role
+
+type Role struct {
+	ID        int    `json:"id"`
+	ServerID  string `json:"serverId"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	Name      string `json:"name"`
+
+	// If set, the role will be displayed separately in the channel member list
+	IsDisplayedSeparately bool `json:"isDisplayedSeparately,omitempty"`
+
+	// If set, this role can be self assigned
+	IsSelfAssignable bool `json:"isSelfAssignable,omitempty"`
+
+	// If set, this role can be mentioned
+	IsMentionable bool `json:"isMentionable,omitempty"`
+
+	// The permissions of the role, e.g. CanReadChats
+	Permissions []string `json:"permissions"`
+
+	// Decimal values of the role colors, a second color makes a gradient
+	Colors []int  `json:"colors,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+
+	// The position of the role in the role hierarchy, 0 is the lowest
+	Position int `json:"position"`
+
+	// If set, this is the base role everyone in the server has
+	IsBase bool `json:"isBase,omitempty"`
+
+	// The ID of the bot this role was created for, if any
+	BotUserID string `json:"botUserId,omitempty"`
+}
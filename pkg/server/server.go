@@ -17,7 +17,7 @@ const (
 
 type Server struct {
 	ID         string `json:"id"`
-	OwnerID    string `json:"ownerid"`
+	OwnerID    string `json:"ownerId"`
 	Type       string `json:"type,omitempty"`
 	Name       string `json:"name"`
 	URL        string `json:"url,omitempty"`
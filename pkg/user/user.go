@@ -87,7 +87,7 @@ func UpdateStatus(c Client, userID string, params *UpdateStatusParams) error {
 
 	_, err = c.PerformRequest(http.MethodPut, endpoint, params)
 	if err != nil {
-		return fmt.Errorf("error updating user status: %v", err)
+		return fmt.Errorf("error updating user status: %w", err)
 	}
 
 	return nil
@@ -99,7 +99,7 @@ func DeleteStatus(c Client, userID string) error {
 
 	_, err = c.PerformRequest(http.MethodDelete, endpoint, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting user status: %v", err)
+		return fmt.Errorf("error deleting user status: %w", err)
 	}
 
 	return nil
@@ -1,7 +1,7 @@
 package guildedgo
 
 import (
-	"errors"
+	"fmt"
 	"strconv"
 )
 
@@ -32,7 +32,7 @@ var _ ReactionService = &reactionService{}
 func (service *reactionService) AddReactionEmote(channelId string, contentId string, emoteId int) error {
 	_, err := service.client.PutRequest(service.endpoints.Default(channelId, contentId, emoteId), nil)
 	if err != nil {
-		return errors.New("Failed to add reaction emote: " + err.Error())
+		return fmt.Errorf("Failed to add reaction emote: %w", err)
 	}
 
 	return nil
@@ -41,7 +41,7 @@ func (service *reactionService) AddReactionEmote(channelId string, contentId str
 func (service *reactionService) DeleteReactionEmote(channelId string, contentId string, emoteId int) error {
 	_, err := service.client.DeleteRequest(service.endpoints.Default(channelId, contentId, emoteId))
 	if err != nil {
-		return errors.New("Failed to delete reaction emote: " + err.Error())
+		return fmt.Errorf("Failed to delete reaction emote: %w", err)
 	}
 
 	return nil
@@ -50,7 +50,7 @@ func (service *reactionService) DeleteReactionEmote(channelId string, contentId
 func (service *reactionService) AddTopicReactionEmote(channelId string, topicId int, emoteId int) error {
 	_, err := service.client.PutRequest(service.endpoints.Topic(channelId, topicId, emoteId), nil)
 	if err != nil {
-		return errors.New("Failed to add topic reaction emote: " + err.Error())
+		return fmt.Errorf("Failed to add topic reaction emote: %w", err)
 	}
 
 	return nil
@@ -59,7 +59,7 @@ func (service *reactionService) AddTopicReactionEmote(channelId string, topicId
 func (service *reactionService) DeleteTopicReactionEmote(channelId string, topicId int, emoteId int) error {
 	_, err := service.client.DeleteRequest(service.endpoints.Topic(channelId, topicId, emoteId))
 	if err != nil {
-		return errors.New("Failed to add topic reaction emote: " + err.Error())
+		return fmt.Errorf("Failed to add topic reaction emote: %w", err)
 	}
 
 	return nil
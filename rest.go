@@ -1,17 +1,50 @@
 package guildedgo
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
-	"io/ioutil"
 	"net/http"
+
+	"github.com/itschip/guildedgo/internal/rest"
 )
 
+// defaultRest is used by DoRequest, which isn't tied to a Client
+var defaultRest = rest.New()
+
+// RateLimitBucket is the rate limit state of a single route, see Client.RateLimits
+type RateLimitBucket = rest.Bucket
+
+// Handler sends a single REST request, see Use
+type Handler = rest.Handler
+
+// Middleware wraps a Handler to observe or change REST requests and responses, see Use
+type Middleware = rest.Middleware
+
+// Use adds middleware around every REST request the client sends, e.g. for logging or metrics.
+// Middleware runs in the order it was added and once per attempt, so retries pass through it again.
+//
+//	c.Use(func(next guildedgo.Handler) guildedgo.Handler {
+//		return func(req *http.Request) (*http.Response, error) {
+//			start := time.Now()
+//			resp, err := next(req)
+//			log.Println(req.Method, req.URL, time.Since(start))
+//			return resp, err
+//		}
+//	})
+func (c *Client) Use(mw ...Middleware) {
+	c.rest.Use(mw...)
+}
+
+// RateLimits returns the rate limit state of every route the client has sent requests to.
+// Routes that are limited hold back new requests until ResetAt.
+func (c *Client) RateLimits() []RateLimitBucket {
+	return c.rest.RateLimiter.Buckets()
+}
+
 func (c *Client) PostRequest(endpoint string, body interface{}) ([]byte, error) {
 	jsonBody, _ := json.Marshal(&body)
 
-	resp, err := DoRequest("POST", endpoint, jsonBody, c.Token)
+	resp, err := c.doRequest("POST", endpoint, jsonBody)
 	if err != nil {
 		return nil, err
 	}
@@ -22,7 +55,7 @@ func (c *Client) PostRequest(endpoint string, body interface{}) ([]byte, error)
 func (c *Client) PostRequestV2(endpoint string, body any, v any) error {
 	jsonBody, _ := json.Marshal(&body)
 
-	resp, err := DoRequest("POST", endpoint, jsonBody, c.Token)
+	resp, err := c.doRequest("POST", endpoint, jsonBody)
 	if err != nil {
 		return err
 	}
@@ -38,7 +71,7 @@ func (c *Client) PostRequestV2(endpoint string, body any, v any) error {
 func (c *Client) PatchRequest(endpoint string, body any, v any) error {
 	jsonBody, _ := json.Marshal(&body)
 
-	resp, err := DoRequest("PATCH", endpoint, jsonBody, c.Token)
+	resp, err := c.doRequest("PATCH", endpoint, jsonBody)
 	if err != nil {
 		return err
 	}
@@ -52,7 +85,7 @@ func (c *Client) PatchRequest(endpoint string, body any, v any) error {
 }
 
 func (c *Client) GetRequest(endpoint string) ([]byte, error) {
-	resp, err := DoRequest("GET", endpoint, nil, c.Token)
+	resp, err := c.doRequest("GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +94,7 @@ func (c *Client) GetRequest(endpoint string) ([]byte, error) {
 }
 
 func (c *Client) GetRequestV2(endpoint string, v any) error {
-	resp, err := DoRequest("GET", endpoint, nil, c.Token)
+	resp, err := c.doRequest("GET", endpoint, nil)
 	if err != nil {
 		return err
 	}
@@ -77,7 +110,7 @@ func (c *Client) GetRequestV2(endpoint string, v any) error {
 func (c *Client) PutRequest(endpoint string, body interface{}) ([]byte, error) {
 	jsonBody, _ := json.Marshal(&body)
 
-	resp, err := DoRequest("PUT", endpoint, jsonBody, c.Token)
+	resp, err := c.doRequest("PUT", endpoint, jsonBody)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +121,7 @@ func (c *Client) PutRequest(endpoint string, body interface{}) ([]byte, error) {
 func (c *Client) PutRequestV2(endpoint string, body interface{}, v any) error {
 	jsonBody, _ := json.Marshal(&body)
 
-	resp, err := DoRequest("PUT", endpoint, jsonBody, c.Token)
+	resp, err := c.doRequest("PUT", endpoint, jsonBody)
 	if err != nil {
 		return err
 	}
@@ -102,7 +135,7 @@ func (c *Client) PutRequestV2(endpoint string, body interface{}, v any) error {
 }
 
 func (c *Client) DeleteRequest(endpoint string) ([]byte, error) {
-	resp, err := DoRequest("DELETE", endpoint, nil, c.Token)
+	resp, err := c.doRequest("DELETE", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -110,49 +143,43 @@ func (c *Client) DeleteRequest(endpoint string) ([]byte, error) {
 	return resp, nil
 }
 
-type responseError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-}
-
 func DoRequest(method string, endpoint string, body []byte, token string) ([]byte, error) {
-	request, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
-	request.Header.Set("Authorization", "Bearer "+token)
-	request.Header.Set("Content-Type", "application/json")
+	return DoRequestWithContext(context.Background(), method, endpoint, body, token)
+}
 
-	// Handle status codes - remember rate limiting
-	resp, err := do(request)
-	if err != nil {
-		return nil, err
-	}
+// DoRequestWithContext is DoRequest with a context that cancels the request, including rate limit and retry waits
+func DoRequestWithContext(ctx context.Context, method string, endpoint string, body []byte, token string) ([]byte, error) {
+	return do(ctx, defaultRest, method, endpoint, body, token)
+}
 
-	return resp, nil
+func (c *Client) doRequest(method string, endpoint string, body []byte) ([]byte, error) {
+	return do(c.Context(), c.rest, method, endpoint, body, c.Token)
 }
 
-func do(req *http.Request) ([]byte, error) {
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
+// do sends the request through the rate limited transport. Rate limited requests are
+// queued and retried, the 429 is only returned once the retries are exhausted.
+// Non 2xx responses are returned as *APIError.
+func do(ctx context.Context, client *rest.Client, method string, endpoint string, body []byte, token string) ([]byte, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	header.Set("Content-Type", "application/json")
 
-	defer resp.Body.Close()
+	req := &rest.Request{
+		Method: method,
+		URL:    endpoint,
+		Header: header,
+		Body:   body,
+	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	resp, err := client.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	switch resp.StatusCode {
-	case http.StatusBadGateway, http.StatusForbidden, http.StatusBadRequest, http.StatusNotFound:
-		var resError responseError
-
-		err := json.Unmarshal(body, &resError)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(resError.Message)
+	err = rest.CheckResponse(req, resp)
+	if err != nil {
+		return nil, err
 	}
 
-	return body, nil
+	return resp.Body, nil
 }
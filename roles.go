@@ -1,16 +1,59 @@
 package guildedgo
 
 import (
-	"log"
+	"fmt"
+	"strconv"
 )
 
+type Role struct {
+	ID        int    `json:"id"`
+	ServerID  string `json:"serverId"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	Name      string `json:"name"`
+
+	// If set, the role will be displayed separately in the channel member list
+	IsDisplayedSeparately bool `json:"isDisplayedSeparately,omitempty"`
+
+	// If set, this role can be self assigned
+	IsSelfAssignable bool `json:"isSelfAssignable,omitempty"`
+
+	// If set, this role can be mentioned
+	IsMentionable bool `json:"isMentionable,omitempty"`
+
+	// The permissions of the role, e.g. CanReadChats
+	Permissions []string `json:"permissions"`
+
+	// Decimal values of the role colors, a second color makes a gradient
+	Colors []int  `json:"colors,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+
+	// The position of the role in the role hierarchy, 0 is the lowest
+	Position int `json:"position"`
+
+	// If set, this is the base role everyone in the server has
+	IsBase bool `json:"isBase,omitempty"`
+
+	// The ID of the bot this role was created for, if any
+	BotUserID string `json:"botUserId,omitempty"`
+}
+
+type RoleResponse struct {
+	Role Role `json:"role"`
+}
+
 type RoleService interface {
-	AddMemberToGroup(groupId string, userId string)
-	RemoveMemberFromGroup(groupId string, userId string)
+	AddMemberToGroup(groupId string, userId string) error
+	RemoveMemberFromGroup(groupId string, userId string) error
+	GetRole(serverId string, roleId int) (*Role, error)
 }
 
 type roleEndpoints struct{}
 
+func (e *roleEndpoints) Role(serverId string, roleId int) string {
+	return guildedApi + "/servers/" + serverId + "/roles/" + strconv.Itoa(roleId)
+}
+
 func (e *roleEndpoints) GroupMember(groupId, userId string) string {
 	return guildedApi + "/groups/" + groupId + "/members/" + userId
 }
@@ -22,20 +65,36 @@ type roleService struct {
 
 var _ RoleService = &roleService{}
 
-func (rs *roleService) AddMemberToGroup(groupId string, userId string) {
+func (rs *roleService) AddMemberToGroup(groupId string, userId string) error {
 	endpoint := rs.endpoints.GroupMember(groupId, userId)
 
 	_, err := rs.client.PutRequest(endpoint, nil)
 	if err != nil {
-		log.Fatalln(err)
+		return fmt.Errorf("Failed to add member to group. Error: %w", err)
 	}
+
+	return nil
 }
 
-func (rs *roleService) RemoveMemberFromGroup(groupId string, userId string) {
+func (rs *roleService) RemoveMemberFromGroup(groupId string, userId string) error {
 	endpoint := rs.endpoints.GroupMember(groupId, userId)
 
 	_, err := rs.client.DeleteRequest(endpoint)
 	if err != nil {
-		log.Fatalln(err)
+		return fmt.Errorf("Failed to remove member from group. Error: %w", err)
 	}
+
+	return nil
+}
+
+func (rs *roleService) GetRole(serverId string, roleId int) (*Role, error) {
+	endpoint := rs.endpoints.Role(serverId, roleId)
+
+	var role RoleResponse
+	err := rs.client.GetRequestV2(endpoint, &role)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get role. Error: %w", err)
+	}
+
+	return &role.Role, nil
 }
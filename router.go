@@ -0,0 +1,355 @@
+package guildedgo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/itschip/guildedgo/internal/command"
+)
+
+// DefaultCommandPrefix is the prefix commands with a Handler are invoked with, see CommandService.SetPrefixes
+const DefaultCommandPrefix = "!"
+
+// CommandContext is an invocation of a command, it is passed to Command.Handler
+type CommandContext struct {
+	Client *Client
+
+	// The event of the message that invoked the command
+	Event *ChatMessageCreated
+
+	// The prefix the command was invoked with, e.g. "!" or the mention of the bot
+	Prefix string
+
+	// The command that was invoked, a subcommand for e.g. !config set
+	Command *Command
+
+	// The command name as it was typed, including the names of parent commands, e.g. "config set"
+	Name string
+
+	// The positional arguments, e.g. user and spamming for !ban user spamming
+	Args []string
+
+	// The --name=value arguments. Flags without a value, e.g. --silent, have an empty value
+	Flags map[string]string
+
+	// The converted values of Command.Params
+	values map[string]any
+}
+
+// Message returns the message that invoked the command
+func (ctx *CommandContext) Message() *ChatMessage {
+	return &ctx.Event.Message
+}
+
+// Arg returns the positional argument i, or an empty string if there are fewer arguments
+func (ctx *CommandContext) Arg(i int) string {
+	if i < 0 || i >= len(ctx.Args) {
+		return ""
+	}
+
+	return ctx.Args[i]
+}
+
+// Flag returns the value of the flag name, and whether it was given at all
+func (ctx *CommandContext) Flag(name string) (string, bool) {
+	value, ok := ctx.Flags[name]
+	return value, ok
+}
+
+// HasFlag reports whether the flag name was given, e.g. --silent
+func (ctx *CommandContext) HasFlag(name string) bool {
+	_, ok := ctx.Flags[name]
+	return ok
+}
+
+// Send sends a message to the channel the command was invoked in
+func (ctx *CommandContext) Send(message *MessageObject) (*ChatMessage, error) {
+	return ctx.Client.Channel.SendMessage(ctx.Event.Message.ChannelID, message)
+}
+
+// Reply replies to the message that invoked the command
+func (ctx *CommandContext) Reply(content string) (*ChatMessage, error) {
+	return ctx.Send(&MessageObject{
+		Content:         content,
+		ReplyMessageIds: []string{ctx.Event.Message.ID},
+	})
+}
+
+// ReplyEmbed replies to the message that invoked the command with an embed
+func (ctx *CommandContext) ReplyEmbed(embed ChatEmbed) (*ChatMessage, error) {
+	return ctx.Send(&MessageObject{
+		Embeds:          []ChatEmbed{embed},
+		ReplyMessageIds: []string{ctx.Event.Message.ID},
+	})
+}
+
+// commandRouter routes ChatMessageCreated events to commands. Commands with a Handler or Subcommands
+// are looked up in a tree by prefix and name, the others by their exact content
+type commandRouter struct {
+	mu       sync.RWMutex
+	prefixes []string
+	primary  string
+	botID    string
+	root     *commandNode
+	exact    map[string][]*Command
+	denied   DeniedHandler
+	roles    roleCache
+	sub      *Subscription
+}
+
+// commandNode is a command with its subcommands, keyed by lower case name and alias
+type commandNode struct {
+	cmd      *Command
+	parent   *commandNode
+	children map[string]*commandNode
+}
+
+func newCommandRouter() *commandRouter {
+	return &commandRouter{
+		prefixes: []string{DefaultCommandPrefix},
+		primary:  DefaultCommandPrefix,
+		root:     &commandNode{children: make(map[string]*commandNode)},
+		exact:    make(map[string][]*Command),
+	}
+}
+
+func newCommandNode(cmd *Command) *commandNode {
+	// Keep a copy, AddCommands passes the address of its loop variable
+	copied := *cmd
+	copied.Subcommands = append([]Command(nil), cmd.Subcommands...)
+
+	n := &commandNode{cmd: &copied, children: make(map[string]*commandNode)}
+	for i := range copied.Subcommands {
+		n.add(&copied.Subcommands[i])
+	}
+
+	return n
+}
+
+// add adds cmd as a child, a command with the same name or alias is replaced
+func (n *commandNode) add(cmd *Command) {
+	child := newCommandNode(cmd)
+	child.parent = n
+
+	n.children[strings.ToLower(cmd.CommandName)] = child
+	for _, alias := range cmd.Aliases {
+		n.children[strings.ToLower(alias)] = child
+	}
+}
+
+// routed reports whether cmd is invoked with a prefix instead of by its exact content
+func routed(cmd *Command) bool {
+	return cmd.Handler != nil || len(cmd.Subcommands) > 0
+}
+
+func (r *commandRouter) add(client *Client, cmd *Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if routed(cmd) {
+		r.root.add(cmd)
+	} else {
+		copied := *cmd
+		r.exact[cmd.CommandName] = append(r.exact[cmd.CommandName], &copied)
+	}
+
+	if r.sub == nil {
+		r.sub = HandleEvent(client, r.route)
+		r.watchRoles(client)
+	}
+}
+
+func (r *commandRouter) setPrefixes(prefixes []string) {
+	prefixes = append([]string(nil), prefixes...)
+
+	primary := ""
+	if len(prefixes) > 0 {
+		primary = prefixes[0]
+	}
+
+	// The longest prefix wins, so that "!!" isn't taken for "!"
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefixes = prefixes
+	r.primary = primary
+}
+
+func (r *commandRouter) getPrefixes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.prefixes...)
+}
+
+func (r *commandRouter) setBotID(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.botID = id
+}
+
+// match strips the prefix from content. Guilded puts mentions in the content as <@userId>
+func (r *commandRouter) match(content string) (prefix string, rest string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.botID != "" {
+		mention := "<@" + r.botID + ">"
+		if strings.HasPrefix(content, mention) {
+			return mention, content[len(mention):], true
+		}
+	}
+
+	for _, p := range r.prefixes {
+		if p != "" && strings.HasPrefix(content, p) {
+			return p, content[len(p):], true
+		}
+	}
+
+	return "", "", false
+}
+
+// resolve finds the command tokens invoke, descending into subcommands as long as the tokens name one.
+// It returns the number of tokens that make up the command name, the rest are arguments
+func (r *commandRouter) resolve(tokens []string) (*commandNode, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	node := r.root
+	n := 0
+	for n < len(tokens) {
+		child := node.children[strings.ToLower(tokens[n])]
+		if child == nil {
+			break
+		}
+
+		node = child
+		n++
+	}
+
+	if n == 0 {
+		return nil, 0
+	}
+
+	return node, n
+}
+
+func (r *commandRouter) route(client *Client, e *ChatMessageCreated) error {
+	r.mu.RLock()
+	botID := r.botID
+	exact := r.exact[e.Message.Content]
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, cmd := range exact {
+		ok, err := r.allowed(&CommandContext{Client: client, Event: e, Command: cmd, Name: cmd.CommandName}, []*Command{cmd})
+		if !ok {
+			errs = append(errs, err)
+			continue
+		}
+
+		errs = append(errs, runExact(client, cmd, e))
+	}
+
+	// Don't answer our own replies
+	if botID != "" && e.Message.CreatedBy == botID {
+		return errors.Join(errs...)
+	}
+
+	return errors.Join(append(errs, r.routePrefixed(client, e))...)
+}
+
+func runExact(client *Client, cmd *Command, e *ChatMessageCreated) error {
+	if cmd.Run != nil {
+		return cmd.Run(client, e)
+	}
+
+	if cmd.Action != nil {
+		cmd.Action(client, e)
+	}
+
+	return nil
+}
+
+func (r *commandRouter) routePrefixed(client *Client, e *ChatMessageCreated) error {
+	prefix, rest, ok := r.match(e.Message.Content)
+	if !ok {
+		return nil
+	}
+
+	tokens, err := command.Tokenize(rest)
+	if err != nil {
+		// Only complain about messages that are meant for one of our commands
+		node, _ := r.resolve(strings.Fields(rest))
+		if node == nil {
+			return nil
+		}
+
+		_, err = (&CommandContext{Client: client, Event: e}).Reply("There is a quote missing in your command")
+		return err
+	}
+
+	node, n := r.resolve(tokens)
+	if node == nil {
+		return nil
+	}
+
+	args := command.Parse(tokens[n:])
+
+	ctx := &CommandContext{
+		Client:  client,
+		Event:   e,
+		Prefix:  prefix,
+		Command: node.cmd,
+		Name:    strings.Join(tokens[:n], " "),
+		Args:    args.Positional,
+		Flags:   args.Flags,
+	}
+
+	// The guards of groups apply to their subcommands
+	var cmds []*Command
+	for n := node; n.cmd != nil; n = n.parent {
+		cmds = append(cmds, n.cmd)
+	}
+
+	ok, err = r.allowed(ctx, cmds)
+	if !ok {
+		return err
+	}
+
+	// A group is only a name for its subcommands
+	if node.cmd.Handler == nil {
+		_, err = ctx.Reply(fmt.Sprintf("Use %s with one of: %s", ctx.Name, strings.Join(subcommandNames(node.cmd), ", ")))
+		return err
+	}
+
+	err = convert(ctx, node.cmd.Params)
+	if err != nil {
+		var argErr *ArgumentError
+		if !errors.As(err, &argErr) {
+			return err
+		}
+
+		_, err = ctx.Reply(argErr.Error())
+		return err
+	}
+
+	return node.cmd.Handler(ctx)
+}
+
+func subcommandNames(cmd *Command) []string {
+	names := make([]string, len(cmd.Subcommands))
+	for i, sub := range cmd.Subcommands {
+		names[i] = sub.CommandName
+	}
+
+	return names
+}
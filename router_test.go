@@ -0,0 +1,240 @@
+package guildedgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo"
+)
+
+func TestCommandRouter(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!ban \"some user\" spam --reason=\"too many links\" --silent"`),
+		chatMessageCreated("2", `,"content":"??ban second"`),
+		chatMessageCreated("3", `,"content":"<@bot> ban third"`),
+		chatMessageCreated("4", `,"content":"!unknown"`),
+		chatMessageCreated("5", `,"content":"ban no prefix"`),
+		chatMessageCreated("6", `,"content":"!ban self","createdBy":"bot"`),
+		chatMessageCreated("7", `,"content":"!ping"`),
+	)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+	c.CommandService.SetPrefixes("!", "??")
+	c.CommandService.SetBotID("bot")
+
+	invocations := make(chan *guildedgo.CommandContext, 10)
+	c.CommandService.AddCommands(&guildedgo.CommandsBuilder{
+		Commands: []guildedgo.Command{
+			{
+				CommandName: "ban",
+				Handler: func(ctx *guildedgo.CommandContext) error {
+					invocations <- ctx
+					return nil
+				},
+			},
+			{
+				CommandName: "ping",
+				Handler: func(ctx *guildedgo.CommandContext) error {
+					invocations <- ctx
+					return nil
+				},
+			},
+		},
+	})
+
+	collect(t, c, "ChatMessageCreated", 7)
+
+	var got []*guildedgo.CommandContext
+	for len(got) < 4 {
+		select {
+		case ctx := <-invocations:
+			got = append(got, ctx)
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for commands, got %d", len(got))
+			t.FailNow()
+		}
+	}
+
+	first := got[0]
+	if first.Prefix != "!" || first.Name != "ban" || !reflect.DeepEqual(first.Args, []string{"some user", "spam"}) {
+		t.Errorf("unexpected invocation %+v", first)
+	}
+
+	if reason, _ := first.Flag("reason"); reason != "too many links" || !first.HasFlag("silent") || first.HasFlag("other") {
+		t.Errorf("unexpected flags %v", first.Flags)
+	}
+
+	if got[1].Prefix != "??" || got[1].Arg(0) != "second" {
+		t.Errorf("expected the longer prefix to match, got %+v", got[1])
+	}
+
+	if got[2].Prefix != "<@bot>" || got[2].Arg(0) != "third" || got[2].Arg(1) != "" {
+		t.Errorf("expected the bot mention to match, got %+v", got[2])
+	}
+
+	if got[3].Name != "ping" || got[3].Message().ID != "7" {
+		t.Errorf("expected only ping after the last ban, got %+v", got[3])
+	}
+}
+
+func TestCommandReply(t *testing.T) {
+	srv := fakeGateway(t, chatMessageCreated("1", `,"content":"!ping"`))
+	defer srv.Close()
+
+	sent := make(chan guildedgo.MessageObject, 1)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/channels/channel/messages" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var msg guildedgo.MessageObject
+		json.NewDecoder(r.Body).Decode(&msg)
+		sent <- msg
+
+		w.Write([]byte(`{"message":{"id":"2","channelId":"channel","content":"pong"}}`))
+	}))
+	defer api.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	c.CommandService.AddCommand(&guildedgo.Command{
+		CommandName: "ping",
+		Handler: func(ctx *guildedgo.CommandContext) error {
+			_, err := ctx.Reply("pong")
+			return err
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case msg := <-sent:
+		if msg.Content != "pong" || !reflect.DeepEqual(msg.ReplyMessageIds, []string{"1"}) {
+			t.Errorf("unexpected reply %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the reply")
+	}
+}
+
+func TestSubcommandsAndAliases(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", `,"content":"!CONFIG Set prefix ?"`),
+		chatMessageCreated("2", `,"content":"!cfg show"`),
+		chatMessageCreated("3", `,"content":"!config"`),
+	)
+	defer srv.Close()
+
+	sent := make(chan guildedgo.MessageObject, 1)
+	api := fakeAPI(t, sent)
+	defer api.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:        "token",
+		BaseURL:      api.URL,
+		WebsocketURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+
+	invocations := make(chan *guildedgo.CommandContext, 2)
+	handler := func(ctx *guildedgo.CommandContext) error {
+		invocations <- ctx
+		return nil
+	}
+
+	c.CommandService.AddCommand(&guildedgo.Command{
+		CommandName: "config",
+		Aliases:     []string{"cfg"},
+		Subcommands: []guildedgo.Command{
+			{CommandName: "set", Handler: handler},
+			{CommandName: "show", Handler: handler},
+		},
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	for _, want := range []struct {
+		command, name string
+		args          []string
+	}{
+		{"set", "CONFIG Set", []string{"prefix", "?"}},
+		{"show", "cfg show", nil},
+	} {
+		select {
+		case ctx := <-invocations:
+			if ctx.Command.CommandName != want.command || ctx.Name != want.name || !reflect.DeepEqual(ctx.Args, want.args) {
+				t.Errorf("expected %s invoked as %q with %q, got %s invoked as %q with %q",
+					want.command, want.name, want.args, ctx.Command.CommandName, ctx.Name, ctx.Args)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for the subcommand")
+			t.FailNow()
+		}
+	}
+
+	select {
+	case msg := <-sent:
+		if msg.Content != "Use config with one of: set, show" {
+			t.Errorf("unexpected reply to the group %q", msg.Content)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the reply to the group")
+	}
+}
+
+func TestCommandsAddedWithContext(t *testing.T) {
+	srv := fakeGateway(t, chatMessageCreated("1", `,"content":"!ping"`))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clients := make(chan *guildedgo.Client, 2)
+	c.WithContext(ctx).CommandService.AddCommand(&guildedgo.Command{
+		CommandName: "ping",
+		Handler: func(ctx *guildedgo.CommandContext) error {
+			clients <- ctx.Client
+			return nil
+		},
+	})
+	c.WithContext(ctx).On("ChatMessageCreated", func(client *guildedgo.Client, v any) {
+		clients <- client
+	})
+
+	// Connects c, not a copy
+	collect(t, c.WithContext(ctx), "ChatMessageCreated", 1)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case client := <-clients:
+			if client != c || client.Context().Err() != nil {
+				t.Error("expected handlers to receive the client WithContext was called on")
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for the handlers")
+			t.FailNow()
+		}
+	}
+}
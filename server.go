@@ -1,13 +1,12 @@
 package guildedgo
 
 import (
-	"errors"
 	"fmt"
 )
 
 type Server struct {
 	ID         string `json:"id"`
-	OwnerID    string `json:"ownerid"`
+	OwnerID    string `json:"ownerId"`
 	Type       string `json:"type,omitempty"`
 	Name       string `json:"name"`
 	URL        string `json:"url,omitempty"`
@@ -63,7 +62,7 @@ func (service *serverService) GetServer(serverId string) (*Server, error) {
 	var server ServerResponse
 	err := service.client.GetRequestV2(endpoint, &server)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to get server. Error: %v", err.Error()))
+		return nil, fmt.Errorf("Failed to get server. Error: %w", err)
 	}
 
 	return &server.Server, nil
@@ -1,6 +1,6 @@
 package guildedgo
 
-import "errors"
+import "fmt"
 
 type XPObject struct {
 	Amount int `json:"amount"`
@@ -39,7 +39,7 @@ func (service *serverXPService) AwardXP(serverID, userID string, xpObject *XPObj
 	var response AwardXPResponse
 	err := service.client.PostRequestV2(service.endpoints.Default(serverID, userID), &xpObject, &response)
 	if err != nil {
-		return nil, errors.New("error awarding xp: " + err.Error())
+		return nil, fmt.Errorf("error awarding xp: %w", err)
 	}
 
 	return &response, nil
@@ -49,7 +49,7 @@ func (service *serverXPService) SetMemberXP(serverID, userID string, xpObject *X
 	var response AwardXPResponse
 	err := service.client.PutRequestV2(service.endpoints.Default(serverID, userID), &xpObject, &response)
 	if err != nil {
-		return nil, errors.New("error setting member xp: " + err.Error())
+		return nil, fmt.Errorf("error setting member xp: %w", err)
 	}
 
 	return &response, nil
@@ -58,7 +58,7 @@ func (service *serverXPService) SetMemberXP(serverID, userID string, xpObject *X
 func (service *serverXPService) AwardRoleXP(serverID, roleID string, xpObject *XPObject) error {
 	err := service.client.PostRequestV2(service.endpoints.Role(serverID, roleID), &xpObject, nil)
 	if err != nil {
-		return errors.New("error awarding role xp: " + err.Error())
+		return fmt.Errorf("error awarding role xp: %w", err)
 	}
 
 	return nil
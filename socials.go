@@ -1,6 +1,6 @@
 package guildedgo
 
-import "errors"
+import "fmt"
 
 type SocialResponse struct {
 	SocialLink `json:"socialLink"`
@@ -10,6 +10,8 @@ type SocialLink struct {
 	Handle    string `json:"handle,omitempty"`
 	ServiceID string `json:"serviceId,omitempty"`
 	Type      string `json:"type"`
+	UserID    string `json:"userId,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
 }
 
 type SocialsService interface {
@@ -31,7 +33,7 @@ func (s *socialsService) GetMemberSocialLinks(serverID string, userID string, so
 	var social SocialResponse
 	err := s.client.GetRequestV2(s.endpoints.Default(serverID, userID, socialType), &social)
 	if err != nil {
-		return nil, errors.New("failed to get social links: " + err.Error())
+		return nil, fmt.Errorf("failed to get social links: %w", err)
 	}
 	return &social.SocialLink, nil
 }
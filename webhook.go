@@ -21,8 +21,11 @@ type BotServerMembershipCreated struct {
 type BotServerMembershipDeleted struct {
 	Server `json:"server"`
 
-	// The ID of the user who deleted this server membership
+	// Deprecated: Guilded never sends this field, use DeletedBy
 	CreatedBy string `json:"createdBy"`
+
+	// The ID of the user who deleted this server membership
+	DeletedBy string `json:"deletedBy"`
 }
 
 type ChatMessageCreated struct {
@@ -64,6 +67,9 @@ type ServerMemberJoined struct {
 	ServerID string `json:"serverId"`
 
 	Member ServerMember `json:"member"`
+
+	// The number of members in the server after this member joined
+	ServerMemberCount int `json:"serverMemberCount"`
 }
 
 type ServerMemberRemoved struct {
@@ -100,7 +106,7 @@ type ServerMemberUpdated struct {
 
 	UserInfo struct {
 		// The ID of the user
-		ID string `json:"userId"`
+		ID string `json:"id"`
 
 		// The nickname that was just updated for the user
 		Nickname string `json:"nickname,omitempty"`
@@ -111,7 +117,8 @@ type ServerRolesUpdated struct {
 	// The ID of the server
 	ServerID string `json:"serverId"`
 
-	MemberRoleIds struct {
+	// The members whose roles changed, with all of their current roles
+	MemberRoleIds []struct {
 		UserID  string `json:"userId"`
 		RoleIDs []int  `json:"roleIds"`
 	} `json:"memberRoleIds"`
@@ -204,7 +211,7 @@ type ForumTopicReactionCreated struct {
 		CreatedBy    string `json:"createdBy"`
 		Emote        `json:"emote"`
 		ForumTopicID int `json:"forumTopicId"`
-	}
+	} `json:"reaction"`
 }
 
 type ForumTopicReactionDeleted struct {
@@ -214,7 +221,7 @@ type ForumTopicReactionDeleted struct {
 		CreatedBy    string `json:"createdBy"`
 		Emote        `json:"emote"`
 		ForumTopicID int `json:"forumTopicId"`
-	}
+	} `json:"reaction"`
 }
 
 type ForumTopicLocked struct {
@@ -242,6 +249,21 @@ type ForumTopicCommentDeleted struct {
 	ForumTopicComment `json:"forumTopicComment"`
 }
 
+type CalendarEventRsvpUpdated struct {
+	ServerID          string `json:"serverId"`
+	CalendarEventRsvp `json:"calendarEventRsvp"`
+}
+
+type CalendarEventRsvpManyUpdated struct {
+	ServerID           string              `json:"serverId"`
+	CalendarEventRsvps []CalendarEventRsvp `json:"calendarEventRsvps"`
+}
+
+type CalendarEventRsvpDeleted struct {
+	ServerID          string `json:"serverId"`
+	CalendarEventRsvp `json:"calendarEventRsvp"`
+}
+
 type ChannelArchived struct {
 	ServerID string        `json:"serverId"`
 	Channel  ServerChannel `json:"channel"`
@@ -251,3 +273,344 @@ type ChannelRestored struct {
 	ServerID string        `json:"serverId"`
 	Channel  ServerChannel `json:"channel"`
 }
+
+type ServerMemberSocialLinkCreated struct {
+	ServerID   string `json:"serverId"`
+	SocialLink `json:"socialLink"`
+}
+
+type ServerMemberSocialLinkUpdated struct {
+	ServerID   string `json:"serverId"`
+	SocialLink `json:"socialLink"`
+}
+
+type ServerMemberSocialLinkDeleted struct {
+	ServerID   string `json:"serverId"`
+	SocialLink `json:"socialLink"`
+}
+
+type RoleCreated struct {
+	ServerID string `json:"serverId"`
+	Role     Role   `json:"role"`
+}
+
+type RoleUpdated struct {
+	ServerID string `json:"serverId"`
+	Role     Role   `json:"role"`
+}
+
+type RoleDeleted struct {
+	ServerID string `json:"serverId"`
+	Role     Role   `json:"role"`
+}
+
+type GroupCreated struct {
+	ServerID string `json:"serverId"`
+	Group    Group  `json:"group"`
+}
+
+type GroupUpdated struct {
+	ServerID string `json:"serverId"`
+	Group    Group  `json:"group"`
+}
+
+type GroupDeleted struct {
+	ServerID string `json:"serverId"`
+	Group    Group  `json:"group"`
+}
+
+type CategoryCreated struct {
+	ServerID string   `json:"serverId"`
+	Category Category `json:"category"`
+}
+
+type CategoryUpdated struct {
+	ServerID string   `json:"serverId"`
+	Category Category `json:"category"`
+}
+
+type CategoryDeleted struct {
+	ServerID string   `json:"serverId"`
+	Category Category `json:"category"`
+}
+
+// ChannelMessageReaction is a reaction to a chat message
+type ChannelMessageReaction struct {
+	ChannelID string `json:"channelId"`
+	MessageID string `json:"messageId"`
+	CreatedBy string `json:"createdBy"`
+	Emote     Emote  `json:"emote"`
+}
+
+type ChannelMessageReactionCreated struct {
+	ServerID string                 `json:"serverId,omitempty"`
+	Reaction ChannelMessageReaction `json:"reaction"`
+}
+
+type ChannelMessageReactionDeleted struct {
+	ServerID string `json:"serverId,omitempty"`
+
+	// The ID of the user who deleted this reaction
+	DeletedBy string                 `json:"deletedBy"`
+	Reaction  ChannelMessageReaction `json:"reaction"`
+}
+
+// ChannelMessageReactionManyDeleted is sent when the reactions of a message are removed in bulk
+type ChannelMessageReactionManyDeleted struct {
+	ServerID  string `json:"serverId,omitempty"`
+	ChannelID string `json:"channelId"`
+	MessageID string `json:"messageId"`
+
+	// The ID of the user who deleted the reactions
+	DeletedBy string `json:"deletedBy"`
+
+	// The number of reactions that were removed
+	Count int `json:"count"`
+
+	// The emote whose reactions were removed, nil if all reactions were removed
+	Emote *Emote `json:"emote,omitempty"`
+}
+
+type ListItemCreated struct {
+	ServerID string   `json:"serverId"`
+	ListItem ListItem `json:"listItem"`
+}
+
+type ListItemUpdated struct {
+	ServerID string   `json:"serverId"`
+	ListItem ListItem `json:"listItem"`
+}
+
+type ListItemDeleted struct {
+	ServerID string   `json:"serverId"`
+	ListItem ListItem `json:"listItem"`
+}
+
+type ListItemCompleted struct {
+	ServerID string   `json:"serverId"`
+	ListItem ListItem `json:"listItem"`
+}
+
+type ListItemUncompleted struct {
+	ServerID string   `json:"serverId"`
+	ListItem ListItem `json:"listItem"`
+}
+
+// DocReaction is a reaction to a doc
+type DocReaction struct {
+	ChannelID string `json:"channelId"`
+	CreatedBy string `json:"createdBy"`
+	Emote     Emote  `json:"emote"`
+	DocID     int    `json:"docId"`
+}
+
+type DocReactionCreated struct {
+	ServerID string      `json:"serverId"`
+	Reaction DocReaction `json:"reaction"`
+}
+
+type DocReactionDeleted struct {
+	ServerID string      `json:"serverId"`
+	Reaction DocReaction `json:"reaction"`
+}
+
+type DocCommentCreated struct {
+	ServerID   string     `json:"serverId"`
+	DocComment DocComment `json:"docComment"`
+}
+
+type DocCommentUpdated struct {
+	ServerID   string     `json:"serverId"`
+	DocComment DocComment `json:"docComment"`
+}
+
+type DocCommentDeleted struct {
+	ServerID   string     `json:"serverId"`
+	DocComment DocComment `json:"docComment"`
+}
+
+// DocCommentReaction is a reaction to a comment on a doc
+type DocCommentReaction struct {
+	ChannelID    string `json:"channelId"`
+	CreatedBy    string `json:"createdBy"`
+	Emote        Emote  `json:"emote"`
+	DocID        int    `json:"docId"`
+	DocCommentID int    `json:"docCommentId"`
+}
+
+type DocCommentReactionCreated struct {
+	ServerID string             `json:"serverId"`
+	Reaction DocCommentReaction `json:"reaction"`
+}
+
+type DocCommentReactionDeleted struct {
+	ServerID string             `json:"serverId"`
+	Reaction DocCommentReaction `json:"reaction"`
+}
+
+type AnnouncementCreated struct {
+	ServerID     string       `json:"serverId"`
+	Announcement Announcement `json:"announcement"`
+}
+
+type AnnouncementUpdated struct {
+	ServerID     string       `json:"serverId"`
+	Announcement Announcement `json:"announcement"`
+}
+
+type AnnouncementDeleted struct {
+	ServerID     string       `json:"serverId"`
+	Announcement Announcement `json:"announcement"`
+}
+
+// AnnouncementReaction is a reaction to an announcement
+type AnnouncementReaction struct {
+	ChannelID      string `json:"channelId"`
+	CreatedBy      string `json:"createdBy"`
+	Emote          Emote  `json:"emote"`
+	AnnouncementID string `json:"announcementId"`
+}
+
+type AnnouncementReactionCreated struct {
+	ServerID string               `json:"serverId"`
+	Reaction AnnouncementReaction `json:"reaction"`
+}
+
+type AnnouncementReactionDeleted struct {
+	ServerID string               `json:"serverId"`
+	Reaction AnnouncementReaction `json:"reaction"`
+}
+
+type AnnouncementCommentCreated struct {
+	ServerID            string              `json:"serverId"`
+	AnnouncementComment AnnouncementComment `json:"announcementComment"`
+}
+
+type AnnouncementCommentUpdated struct {
+	ServerID            string              `json:"serverId"`
+	AnnouncementComment AnnouncementComment `json:"announcementComment"`
+}
+
+type AnnouncementCommentDeleted struct {
+	ServerID            string              `json:"serverId"`
+	AnnouncementComment AnnouncementComment `json:"announcementComment"`
+}
+
+// AnnouncementCommentReaction is a reaction to a comment on an announcement
+type AnnouncementCommentReaction struct {
+	ChannelID             string `json:"channelId"`
+	CreatedBy             string `json:"createdBy"`
+	Emote                 Emote  `json:"emote"`
+	AnnouncementID        string `json:"announcementId"`
+	AnnouncementCommentID int    `json:"announcementCommentId"`
+}
+
+type AnnouncementCommentReactionCreated struct {
+	ServerID string                      `json:"serverId"`
+	Reaction AnnouncementCommentReaction `json:"reaction"`
+}
+
+type AnnouncementCommentReactionDeleted struct {
+	ServerID string                      `json:"serverId"`
+	Reaction AnnouncementCommentReaction `json:"reaction"`
+}
+
+// CalendarEventReaction is a reaction to a calendar event
+type CalendarEventReaction struct {
+	ChannelID       string `json:"channelId"`
+	CreatedBy       string `json:"createdBy"`
+	Emote           Emote  `json:"emote"`
+	CalendarEventID int    `json:"calendarEventId"`
+}
+
+type CalendarEventReactionCreated struct {
+	ServerID string                `json:"serverId"`
+	Reaction CalendarEventReaction `json:"reaction"`
+}
+
+type CalendarEventReactionDeleted struct {
+	ServerID string                `json:"serverId"`
+	Reaction CalendarEventReaction `json:"reaction"`
+}
+
+type CalendarEventCommentCreated struct {
+	ServerID             string               `json:"serverId"`
+	CalendarEventComment CalendarEventComment `json:"calendarEventComment"`
+}
+
+type CalendarEventCommentUpdated struct {
+	ServerID             string               `json:"serverId"`
+	CalendarEventComment CalendarEventComment `json:"calendarEventComment"`
+}
+
+type CalendarEventCommentDeleted struct {
+	ServerID             string               `json:"serverId"`
+	CalendarEventComment CalendarEventComment `json:"calendarEventComment"`
+}
+
+// CalendarEventCommentReaction is a reaction to a comment on a calendar event
+type CalendarEventCommentReaction struct {
+	ChannelID              string `json:"channelId"`
+	CreatedBy              string `json:"createdBy"`
+	Emote                  Emote  `json:"emote"`
+	CalendarEventID        int    `json:"calendarEventId"`
+	CalendarEventCommentID int    `json:"calendarEventCommentId"`
+}
+
+type CalendarEventCommentReactionCreated struct {
+	ServerID string                       `json:"serverId"`
+	Reaction CalendarEventCommentReaction `json:"reaction"`
+}
+
+type CalendarEventCommentReactionDeleted struct {
+	ServerID string                       `json:"serverId"`
+	Reaction CalendarEventCommentReaction `json:"reaction"`
+}
+
+type CalendarEventSeriesUpdated struct {
+	ServerID            string              `json:"serverId"`
+	CalendarEventSeries CalendarEventSeries `json:"calendarEventSeries"`
+
+	// The calendar event the series was updated from, if any
+	CalendarEventID int `json:"calendarEventId,omitempty"`
+}
+
+type CalendarEventSeriesDeleted struct {
+	ServerID            string              `json:"serverId"`
+	CalendarEventSeries CalendarEventSeries `json:"calendarEventSeries"`
+
+	// The calendar event the series was deleted from, if any
+	CalendarEventID int `json:"calendarEventId,omitempty"`
+}
+
+// ForumTopicCommentReaction is a reaction to a comment on a forum topic
+type ForumTopicCommentReaction struct {
+	ChannelID           string `json:"channelId"`
+	CreatedBy           string `json:"createdBy"`
+	Emote               Emote  `json:"emote"`
+	ForumTopicID        int    `json:"forumTopicId"`
+	ForumTopicCommentID int    `json:"forumTopicCommentId"`
+}
+
+type ForumTopicCommentReactionCreated struct {
+	ServerID string                    `json:"serverId"`
+	Reaction ForumTopicCommentReaction `json:"reaction"`
+}
+
+type ForumTopicCommentReactionDeleted struct {
+	ServerID string                    `json:"serverId"`
+	Reaction ForumTopicCommentReaction `json:"reaction"`
+}
+
+type UserStatusCreated struct {
+	UserID     string     `json:"userId"`
+	UserStatus UserStatus `json:"userStatus"`
+
+	// The ISO 8601 timestamp that the status expires at, if any
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+type UserStatusDeleted struct {
+	UserID     string     `json:"userId"`
+	UserStatus UserStatus `json:"userStatus"`
+}
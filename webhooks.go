@@ -1,6 +1,6 @@
 package guildedgo
 
-import "errors"
+import "fmt"
 
 type WebhookObject struct {
 	Name      string `json:"name"`
@@ -39,7 +39,7 @@ func (service *webhookService) CreateWebhook(serverId string, webhookObject *Web
 
 	err := service.client.PostRequestV2(service.endpoints.Default(serverId), &webhookObject, &webhook)
 	if err != nil {
-		return nil, errors.New("Failed to create webhook: " + err.Error())
+		return nil, fmt.Errorf("Failed to create webhook: %w", err)
 	}
 
 	return &webhook, nil
@@ -50,7 +50,7 @@ func (service *webhookService) GetWebhooks(serverId string) ([]Webhook, error) {
 
 	err := service.client.GetRequestV2(service.endpoints.Default(serverId), &webhooks)
 	if err != nil {
-		return nil, errors.New("Failed to get webhooks: " + err.Error())
+		return nil, fmt.Errorf("Failed to get webhooks: %w", err)
 	}
 
 	return webhooks, nil
@@ -61,7 +61,7 @@ func (service *webhookService) GetWebhook(serverId string, webhookId string) (*W
 
 	err := service.client.GetRequestV2(service.endpoints.Get(serverId, webhookId), &webhook)
 	if err != nil {
-		return nil, errors.New("Failed to get webhook: " + err.Error())
+		return nil, fmt.Errorf("Failed to get webhook: %w", err)
 	}
 
 	return &webhook, nil
@@ -72,7 +72,7 @@ func (service *webhookService) UpdateWebhook(serverId string, webhookId string,
 
 	err := service.client.PutRequestV2(service.endpoints.Get(serverId, webhookId), &webhookObject, &webhook)
 	if err != nil {
-		return nil, errors.New("Failed to update webhook: " + err.Error())
+		return nil, fmt.Errorf("Failed to update webhook: %w", err)
 	}
 
 	return &webhook, nil
@@ -81,7 +81,7 @@ func (service *webhookService) UpdateWebhook(serverId string, webhookId string,
 func (service *webhookService) DeleteWebhook(serverId string, webhookId string) error {
 	_, err := service.client.DeleteRequest(service.endpoints.Get(serverId, webhookId))
 	if err != nil {
-		return errors.New("Failed to delete webhook: " + err.Error())
+		return fmt.Errorf("Failed to delete webhook: %w", err)
 	}
 
 	return nil
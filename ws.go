@@ -3,56 +3,124 @@ package guildedgo
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+
+	"github.com/itschip/guildedgo/internal/gateway"
 )
 
-var interfaces = make(map[string]any)
+// eventRegistry maps gateway event names to the types they are decoded into
+var eventRegistry = gateway.NewRegistry()
 
 func init() {
-	interfaces["BotServerMembershipCreated"] = &BotServerMembershipCreated{}
-	interfaces["BotServerMembershipDeleted"] = &BotServerMembershipDeleted{}
-	interfaces["ChatMessageCreated"] = &ChatMessageCreated{}
-	interfaces["ChatMessageUpdated"] = &ChatMessageUpdated{}
-	interfaces["ChatMessageDeleted"] = &ChatMessageDeleted{}
-	interfaces["ServerMemberJoined"] = &ServerMemberJoined{}
-	interfaces["ServerMemberRemoved"] = &ServerMemberRemoved{}
-	interfaces["ServerMemberBanned"] = &ServerMemberBanned{}
-	interfaces["ServerMemberUnbanned"] = &ServerMemberUnbanned{}
-	interfaces["ServerMemberUpdated"] = &ServerMemberUpdated{}
-	interfaces["ServerRolesUpdated"] = &ServerRolesUpdated{}
-	interfaces["ServerChannelCreated"] = &ServerChannelCreated{}
-	interfaces["ServerChannelUpdated"] = &ServerChannelUpdated{}
-	interfaces["ServerChannelDeleted"] = &ServerChannelDeleted{}
-	interfaces["ServerWebhookCreated"] = &ServerWebhookCreated{}
-	interfaces["ServerWebhookUpdated"] = &ServerWebhookUpdated{}
-	interfaces["ChannelArchived"] = &ChannelArchived{}
-	interfaces["ChannelRestored"] = &ChannelRestored{}
-	interfaces["DocCreated"] = &DocCreated{}
-	interfaces["DocUpdated"] = &DocUpdated{}
-	interfaces["DocDeleted"] = &DocDeleted{}
-	interfaces["CalendarEventCreated"] = &CalendarEventCreated{}
-	interfaces["CalendarEventUpdated"] = &CalendarEventUpdated{}
-	interfaces["CalendarEventDeleted"] = &CalendarEventDeleted{}
-	interfaces["ForumTopicCreated"] = &ForumTopicCreated{}
-	interfaces["ForumTopicUpdated"] = &ForumTopicUpdated{}
-	interfaces["ForumTopicDeleted"] = &ForumTopicDeleted{}
-	interfaces["ForumTopicPinned"] = &ForumTopicPinned{}
-	interfaces["ForumTopicUnpinned"] = &ForumTopicUnpinned{}
-	interfaces["ForumTopicReactionCreated"] = &ForumTopicReactionCreated{}
-	interfaces["ForumTopicReactionDeleted"] = &ForumTopicReactionDeleted{}
-	interfaces["ForumTopicLocked"] = &ForumTopic{}
-	interfaces["ForumTopicUnlocked"] = &ForumTopic{}
-	interfaces["ForumTopicCommentCreated"] = &ForumTopicComment{}
-	interfaces["ForumTopicCommentUpdated"] = &ForumTopicComment{}
-	interfaces["ForumTopicCommentDeleted"] = &ForumTopicComment{}
-	interfaces["CalendarEventRsvpUpdated"] = &CalendarEventRsvp{}
-	interfaces["CalendarEventRsvpManyUpdated"] = &[]CalendarEventRsvp{}
-	interfaces["CalendarEventRsvpDeleted"] = &CalendarEventRsvp{}
+	gateway.Register[BotServerMembershipCreated](eventRegistry, "BotServerMembershipCreated")
+	gateway.Register[BotServerMembershipDeleted](eventRegistry, "BotServerMembershipDeleted")
+	gateway.Register[ChatMessageCreated](eventRegistry, "ChatMessageCreated")
+	gateway.Register[ChatMessageUpdated](eventRegistry, "ChatMessageUpdated")
+	gateway.Register[ChatMessageDeleted](eventRegistry, "ChatMessageDeleted")
+	gateway.Register[ServerMemberJoined](eventRegistry, "ServerMemberJoined")
+	gateway.Register[ServerMemberRemoved](eventRegistry, "ServerMemberRemoved")
+	gateway.Register[ServerMemberBanned](eventRegistry, "ServerMemberBanned")
+	gateway.Register[ServerMemberUnbanned](eventRegistry, "ServerMemberUnbanned")
+	gateway.Register[ServerMemberUpdated](eventRegistry, "ServerMemberUpdated")
+	gateway.Register[ServerRolesUpdated](eventRegistry, "ServerRolesUpdated")
+	gateway.Register[ServerChannelCreated](eventRegistry, "ServerChannelCreated")
+	gateway.Register[ServerChannelUpdated](eventRegistry, "ServerChannelUpdated")
+	gateway.Register[ServerChannelDeleted](eventRegistry, "ServerChannelDeleted")
+	gateway.Register[ServerWebhookCreated](eventRegistry, "ServerWebhookCreated")
+	gateway.Register[ServerWebhookUpdated](eventRegistry, "ServerWebhookUpdated")
+	gateway.Register[ChannelArchived](eventRegistry, "ChannelArchived")
+	gateway.Register[ChannelRestored](eventRegistry, "ChannelRestored")
+	gateway.Register[DocCreated](eventRegistry, "DocCreated")
+	gateway.Register[DocUpdated](eventRegistry, "DocUpdated")
+	gateway.Register[DocDeleted](eventRegistry, "DocDeleted")
+	gateway.Register[CalendarEventCreated](eventRegistry, "CalendarEventCreated")
+	gateway.Register[CalendarEventUpdated](eventRegistry, "CalendarEventUpdated")
+	gateway.Register[CalendarEventDeleted](eventRegistry, "CalendarEventDeleted")
+	gateway.Register[ForumTopicCreated](eventRegistry, "ForumTopicCreated")
+	gateway.Register[ForumTopicUpdated](eventRegistry, "ForumTopicUpdated")
+	gateway.Register[ForumTopicDeleted](eventRegistry, "ForumTopicDeleted")
+	gateway.Register[ForumTopicPinned](eventRegistry, "ForumTopicPinned")
+	gateway.Register[ForumTopicUnpinned](eventRegistry, "ForumTopicUnpinned")
+	gateway.Register[ForumTopicReactionCreated](eventRegistry, "ForumTopicReactionCreated")
+	gateway.Register[ForumTopicReactionDeleted](eventRegistry, "ForumTopicReactionDeleted")
+	gateway.Register[ForumTopicLocked](eventRegistry, "ForumTopicLocked")
+	gateway.Register[ForumTopicUnlocked](eventRegistry, "ForumTopicUnlocked")
+	gateway.Register[ForumTopicCommentCreated](eventRegistry, "ForumTopicCommentCreated")
+	gateway.Register[ForumTopicCommentUpdated](eventRegistry, "ForumTopicCommentUpdated")
+	gateway.Register[ForumTopicCommentDeleted](eventRegistry, "ForumTopicCommentDeleted")
+	gateway.Register[CalendarEventRsvpUpdated](eventRegistry, "CalendarEventRsvpUpdated")
+	gateway.Register[CalendarEventRsvpManyUpdated](eventRegistry, "CalendarEventRsvpManyUpdated")
+	gateway.Register[CalendarEventRsvpDeleted](eventRegistry, "CalendarEventRsvpDeleted")
+	gateway.Register[ServerMemberSocialLinkCreated](eventRegistry, "ServerMemberSocialLinkCreated")
+	gateway.Register[ServerMemberSocialLinkUpdated](eventRegistry, "ServerMemberSocialLinkUpdated")
+	gateway.Register[ServerMemberSocialLinkDeleted](eventRegistry, "ServerMemberSocialLinkDeleted")
+	gateway.Register[RoleCreated](eventRegistry, "RoleCreated")
+	gateway.Register[RoleUpdated](eventRegistry, "RoleUpdated")
+	gateway.Register[RoleDeleted](eventRegistry, "RoleDeleted")
+	gateway.Register[GroupCreated](eventRegistry, "GroupCreated")
+	gateway.Register[GroupUpdated](eventRegistry, "GroupUpdated")
+	gateway.Register[GroupDeleted](eventRegistry, "GroupDeleted")
+	gateway.Register[CategoryCreated](eventRegistry, "CategoryCreated")
+	gateway.Register[CategoryUpdated](eventRegistry, "CategoryUpdated")
+	gateway.Register[CategoryDeleted](eventRegistry, "CategoryDeleted")
+	gateway.Register[ChannelMessageReactionCreated](eventRegistry, "ChannelMessageReactionCreated")
+	gateway.Register[ChannelMessageReactionDeleted](eventRegistry, "ChannelMessageReactionDeleted")
+	gateway.Register[ChannelMessageReactionManyDeleted](eventRegistry, "ChannelMessageReactionManyDeleted")
+	gateway.Register[ListItemCreated](eventRegistry, "ListItemCreated")
+	gateway.Register[ListItemUpdated](eventRegistry, "ListItemUpdated")
+	gateway.Register[ListItemDeleted](eventRegistry, "ListItemDeleted")
+	gateway.Register[ListItemCompleted](eventRegistry, "ListItemCompleted")
+	gateway.Register[ListItemUncompleted](eventRegistry, "ListItemUncompleted")
+	gateway.Register[DocReactionCreated](eventRegistry, "DocReactionCreated")
+	gateway.Register[DocReactionDeleted](eventRegistry, "DocReactionDeleted")
+	gateway.Register[DocCommentCreated](eventRegistry, "DocCommentCreated")
+	gateway.Register[DocCommentUpdated](eventRegistry, "DocCommentUpdated")
+	gateway.Register[DocCommentDeleted](eventRegistry, "DocCommentDeleted")
+	gateway.Register[DocCommentReactionCreated](eventRegistry, "DocCommentReactionCreated")
+	gateway.Register[DocCommentReactionDeleted](eventRegistry, "DocCommentReactionDeleted")
+	gateway.Register[AnnouncementCreated](eventRegistry, "AnnouncementCreated")
+	gateway.Register[AnnouncementUpdated](eventRegistry, "AnnouncementUpdated")
+	gateway.Register[AnnouncementDeleted](eventRegistry, "AnnouncementDeleted")
+	gateway.Register[AnnouncementReactionCreated](eventRegistry, "AnnouncementReactionCreated")
+	gateway.Register[AnnouncementReactionDeleted](eventRegistry, "AnnouncementReactionDeleted")
+	gateway.Register[AnnouncementCommentCreated](eventRegistry, "AnnouncementCommentCreated")
+	gateway.Register[AnnouncementCommentUpdated](eventRegistry, "AnnouncementCommentUpdated")
+	gateway.Register[AnnouncementCommentDeleted](eventRegistry, "AnnouncementCommentDeleted")
+	gateway.Register[AnnouncementCommentReactionCreated](eventRegistry, "AnnouncementCommentReactionCreated")
+	gateway.Register[AnnouncementCommentReactionDeleted](eventRegistry, "AnnouncementCommentReactionDeleted")
+	gateway.Register[CalendarEventReactionCreated](eventRegistry, "CalendarEventReactionCreated")
+	gateway.Register[CalendarEventReactionDeleted](eventRegistry, "CalendarEventReactionDeleted")
+	gateway.Register[CalendarEventCommentCreated](eventRegistry, "CalendarEventCommentCreated")
+	gateway.Register[CalendarEventCommentUpdated](eventRegistry, "CalendarEventCommentUpdated")
+	gateway.Register[CalendarEventCommentDeleted](eventRegistry, "CalendarEventCommentDeleted")
+	gateway.Register[CalendarEventCommentReactionCreated](eventRegistry, "CalendarEventCommentReactionCreated")
+	gateway.Register[CalendarEventCommentReactionDeleted](eventRegistry, "CalendarEventCommentReactionDeleted")
+	gateway.Register[CalendarEventSeriesUpdated](eventRegistry, "CalendarEventSeriesUpdated")
+	gateway.Register[CalendarEventSeriesDeleted](eventRegistry, "CalendarEventSeriesDeleted")
+	gateway.Register[ForumTopicCommentReactionCreated](eventRegistry, "ForumTopicCommentReactionCreated")
+	gateway.Register[ForumTopicCommentReactionDeleted](eventRegistry, "ForumTopicCommentReactionDeleted")
+	gateway.Register[UserStatusCreated](eventRegistry, "UserStatusCreated")
+	gateway.Register[UserStatusDeleted](eventRegistry, "UserStatusDeleted")
+
+	// Emitted by the client itself to report the connection state
+	gateway.Register[Connected](eventRegistry, "Connected")
+	gateway.Register[Disconnected](eventRegistry, "Disconnected")
+	gateway.Register[Reconnecting](eventRegistry, "Reconnecting")
+	gateway.Register[Resumed](eventRegistry, "Resumed")
+	gateway.Register[ResyncRequired](eventRegistry, "ResyncRequired")
 }
 
+// RawEvent is a message as it was received from the gateway, see Client.OnRaw
 type RawEvent struct {
-	T    string          `json:"t"`
-	S    string          `json:"s"`
+	// The op code, 0 for events
+	OP int `json:"op"`
+
+	// The event name, e.g. ChatMessageCreated
+	T string `json:"t"`
+
+	// The message ID, used to resume the session
+	S string `json:"s"`
+
+	// The event data
 	Data json.RawMessage `json:"d"`
 }
 
@@ -65,18 +133,46 @@ func (c *Client) onEvent(msg []byte) {
 
 	err = decoder.Decode(&re)
 	if err != nil {
-		log.Println("Failed to decode raw event")
+		c.logger.Error("Failed to decode raw event", "error", err)
+		return
 	}
 
-	eventType := interfaces[re.T]
-	err = json.Unmarshal(re.Data, eventType)
-	if err != nil {
-		log.Printf("Failed to unmarshal event data for %q. Error: %s", re.T, err.Error())
+	// Waiters and collectors get the event right away, they may be waiting in a handler
+	// that holds up the events of its channel. Otherwise events are decoded by the worker pool
+	var v any
+	offered := false
+	if re.OP == gateway.OpEvent && c.dispatcher.Watched(re.T) {
+		v = c.decode(re)
+		c.dispatcher.Offer(re.T, v)
+		offered = true
 	}
 
-	// Is this smart? Probably not.
-	eventsCB := c.events[re.T]
-	for _, event := range eventsCB {
-		event.Callback(c, eventType)
+	// Events are handled by the worker pool, so slow handlers don't hold up the connection
+	c.pool.Submit(gateway.OrderingKey(re.Data), func() {
+		c.raw.Dispatch(re.T, re, re.Data)
+
+		// Resume and resync op codes are handled by the session
+		if re.OP != gateway.OpEvent {
+			return
+		}
+
+		if !offered {
+			v = c.decode(re)
+		}
+
+		c.emit(re.T, v, re.Data)
+	})
+}
+
+// decode returns a new value of the event, handlers may keep it or hand it to other goroutines.
+// Events that aren't modelled yet are returned as they are
+func (c *Client) decode(re *RawEvent) any {
+	v, err := eventRegistry.Decode(re.T, re.Data)
+	if err == gateway.ErrUnknownEvent {
+		return re
+	} else if err != nil {
+		c.logger.Error("Failed to decode event", "event", re.T, "channel_id", gateway.ChannelID(re.Data), "error", err)
 	}
+
+	return v
 }
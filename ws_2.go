@@ -1,14 +1,17 @@
 package guildedgo
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/itschip/guildedgo/internal/gateway"
 )
 
 type RawEvent2 struct {
@@ -22,157 +25,223 @@ type WelcomeOP struct {
 	HeartbeatInterval int `json:"heartbeatIntervalMs"`
 }
 
-var (
-	newline = []byte{'\n'}
-	space   = []byte{' '}
-)
+// Connected is emitted whenever the gateway connection is established, including reconnects
+type Connected struct{}
+
+// Disconnected is emitted when the gateway connection is lost. The client reconnects on its own
+type Disconnected struct {
+	// Why the connection was lost
+	Err error
+}
 
-func (c *Client) Open() {
-	var err error
+// Reconnecting is emitted before every reconnect attempt
+type Reconnecting struct {
+	// The number of the attempt, starting at 1
+	Attempt int
+
+	// How long the client waits before the attempt
+	Delay time.Duration
+}
+
+// Resumed is emitted once Guilded replayed the events missed while the client was disconnected
+type Resumed struct {
+	LastMessageID string
+}
+
+// ResyncRequired is emitted when Guilded can't replay the events missed while the client was disconnected.
+// The client reconnects without resuming, so any state kept from events should be fetched again.
+type ResyncRequired struct {
+	// The op code Guilded sent, 8 for an invalid last message ID or 9 for an internal error
+	Op int
+}
+
+// Open connects to the Guilded gateway and starts listening for events.
+// Dropped connections are re-established with exponential backoff, listen to the
+// "Connected", "Disconnected" and "Reconnecting" events to follow the connection state.
+// After a reconnect, events missed in between are replayed and "Resumed" is emitted,
+// or "ResyncRequired" if Guilded can't replay them.
+// An error is only returned if the first connection attempt fails.
+func (c *Client) Open() error {
+	c = c.base()
 
 	c.Lock()
 	defer c.Unlock()
 
-	if c.conn != nil {
-		return
+	if c.session == nil {
+		c.session = gateway.New(gateway.Config{
+			URL:              c.websocketURL,
+			Dialer:           c.dialer,
+			LastMessageID:    c.lastMessageID,
+			MissedHeartbeats: c.missedHeartbeats,
+			Header: func() http.Header {
+				header := http.Header{}
+				header.Add("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+				header.Add("User-Agent", c.userAgent)
+				return header
+			},
+			OnMessage: c.onEvent,
+			Logger:    c.logger,
+			OnConnect: func() {
+				c.logger.Debug("Connected to websocket")
+				c.post("Connected", &Connected{})
+			},
+			OnDisconnect: func(err error) {
+				c.logger.Warn("Lost websocket connection", "error", err)
+				c.post("Disconnected", &Disconnected{Err: err})
+			},
+			OnReconnect: func(attempt int, delay time.Duration) {
+				c.post("Reconnecting", &Reconnecting{Attempt: attempt, Delay: delay})
+			},
+			OnResume: func(lastMessageID string) {
+				c.post("Resumed", &Resumed{LastMessageID: lastMessageID})
+			},
+			OnResync: func(op int) {
+				c.post("ResyncRequired", &ResyncRequired{Op: op})
+			},
+		})
 	}
 
-	header := http.Header{}
-	header.Add("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	c.pool.Start()
 
-	c.conn, _, err = websocket.DefaultDialer.Dial("wss://www.guilded.gg/websocket/v1", header)
+	err := c.session.Open()
 	if err != nil {
-		log.Fatalln("Failed to connect to websocket: ", err.Error())
+		c.pool.Stop(context.Background())
 	}
 
-	c.conn.SetCloseHandler(func(code int, text string) error {
-		return nil
-	})
+	return err
+}
 
-	defer func() {
-		if err != nil {
-			c.conn.Close()
-			c.conn = nil
-		}
-	}()
+// LastMessageID returns the ID of the last event received from the gateway.
+// Store it and pass it as Config.LastMessageID to resume after a restart.
+func (c *Client) LastMessageID() string {
+	c = c.base()
 
-	// Get the welcome message from Guilded
-	_, m, err := c.conn.ReadMessage()
-	if err != nil {
-		log.Fatalln("Failed to read message: ", err.Error())
-	}
-	m = bytes.TrimSpace(bytes.Replace(m, newline, space, -1))
+	c.RLock()
+	defer c.RUnlock()
 
-	event := c.onWelcomeMessage(m)
-	if event == nil {
-		return
+	if c.session == nil {
+		return c.lastMessageID
 	}
 
-	c.listening = make(chan struct{})
-
-	go c.heartbeat(c.conn, c.listening, event.HeartbeatInterval)
-	go c.listen(c.conn, c.listening)
-
-	log.Println("Listening for messages in main")
+	return c.session.LastMessageID()
 }
 
-func (c *Client) listen(wsConn *websocket.Conn, listening <-chan struct{}) {
-	for {
-		select {
-		case <-listening:
-			return
-		default:
-			_, msg, err := wsConn.ReadMessage()
-			if err != nil {
-				log.Println("Failed to read message: ", err.Error())
-				return
-			}
-
-			msg = bytes.TrimSpace(bytes.Replace(msg, newline, space, -1))
-
-			c.onEvent(msg)
-		}
-	}
-}
+// Stats is a snapshot of the gateway connection health, see Client.Stats
+type Stats = gateway.SessionStats
 
-func (c *Client) heartbeat(wsConn *websocket.Conn, listening <-chan struct{}, intervalMs int) {
-	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			log.Println("Sending heartbeat")
-			err := wsConn.WriteMessage(websocket.TextMessage, []byte(""))
-			if err != nil {
-				log.Println("Failed to send heartbeat: ", err.Error())
-			}
-		case <-listening:
-			return
-		}
-	}
-}
+// Stats reports the heartbeat latency, how long the connection has been up, how often it reconnected,
+// and how many events of each type were received since Open
+func (c *Client) Stats() Stats {
+	c = c.base()
 
-func (c *Client) Close() {
-	c.Lock()
+	c.RLock()
+	defer c.RUnlock()
 
-	if c.listening != nil {
-		log.Println("Closing listening channel")
-		close(c.listening)
-		c.listening = nil
+	if c.session == nil {
+		return Stats{}
 	}
 
-	if c.conn != nil {
-		log.Println("Closing websocket connection")
+	return c.session.Stats()
+}
 
-		c.wsMutex.Lock()
-		err := c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		c.wsMutex.Unlock()
-		if err != nil {
-			log.Println("Failed to write close message: ", err.Error())
-		}
+// Close sends a close frame to Guilded, closes the connection and waits for the handlers
+// of events that were already received, for up to Config.ShutdownTimeout
+func (c *Client) Close() {
+	c = c.base()
 
-		time.Sleep(1 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
 
-		log.Println("Closing websocket connection")
+	err := c.shutdown(ctx)
+	if err != nil {
+		c.logger.Warn("Failed to shut down", "error", err)
+	}
+}
 
-		err = c.conn.Close()
-		if err != nil {
-			log.Println("Failed to close websocket connection: ", err.Error())
-		}
+func (c *Client) shutdown(ctx context.Context) error {
+	// Don't hold the lock while waiting, Stats and LastMessageID need it
+	c.RLock()
+	session := c.session
+	c.RUnlock()
 
-		c.conn = nil
+	if session == nil {
+		return nil
 	}
 
-	c.Unlock()
+	err := session.Shutdown(ctx)
+	if err != nil {
+		c.logger.Warn("Failed to close websocket connection", "error", err)
+	}
 
-	log.Println("Closed websocket connection")
+	// Let the handlers finish the events that were already received
+	err = c.pool.Stop(ctx)
+	if err != nil {
+		return fmt.Errorf("guildedgo: event handlers did not finish: %w", err)
+	}
 
-	return
+	c.logger.Debug("Closed websocket connection")
+	return nil
 }
 
-func (c *Client) onWelcomeMessage(msg []byte) *WelcomeOP {
-	var err error
-	reader := bytes.NewBuffer(msg)
+var (
+	// ErrInterrupted is returned by Run when the process received SIGINT or SIGTERM
+	ErrInterrupted = errors.New("guildedgo: interrupted")
+
+	// ErrUnauthorized is returned by Run when Guilded rejected the token while reconnecting
+	ErrUnauthorized = gateway.ErrUnauthorized
 
-	var re RawEvent2
-	decoder := json.NewDecoder(reader)
+	// ErrClosed is returned by Run when the connection was closed elsewhere, e.g. by calling Close
+	ErrClosed = gateway.ErrClosed
+)
 
-	err = decoder.Decode(&re)
+// Run opens the connection and blocks until ctx is done, the process receives SIGINT or SIGTERM,
+// or the session ends because Guilded rejected the token or it was closed. It then closes the connection
+// like Close and returns why it stopped: the context's error, ErrInterrupted, ErrUnauthorized or ErrClosed.
+// If the event handlers don't finish within Config.ShutdownTimeout, that is reported along with it.
+//
+//	err := client.Run(ctx)
+//	if err != nil && !errors.Is(err, guildedgo.ErrInterrupted) {
+//		log.Fatal(err)
+//	}
+func (c *Client) Run(ctx context.Context) error {
+	c = c.base()
+
+	err := c.Open()
 	if err != nil {
-		log.Println("Failed to decode raw event")
+		return err
 	}
 
-	if re.OP != 1 {
-		log.Println("Expected OP code 1, got", re.OP)
-		return nil
+	c.Lock()
+	if c.interrupt == nil {
+		c.interrupt = make(chan os.Signal, 1)
+	}
+	interrupt := c.interrupt
+	done := c.session.Done()
+	c.Unlock()
+
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	var reason error
+	select {
+	case <-ctx.Done():
+		reason = context.Cause(ctx)
+	case sig := <-interrupt:
+		reason = fmt.Errorf("%w (%s)", ErrInterrupted, sig)
+	case <-done:
+		reason = c.session.Err()
+		if reason == nil {
+			reason = ErrClosed
+		}
 	}
 
-	var h WelcomeOP
-	err = json.Unmarshal(re.Data, &h)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	err = c.shutdown(shutdownCtx)
 	if err != nil {
-		log.Printf("Failed to unmarshal event data for %q. Error: %s", re.T, err.Error())
+		return errors.Join(reason, err)
 	}
 
-	return &h
+	return reason
 }
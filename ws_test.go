@@ -0,0 +1,348 @@
+package guildedgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/itschip/guildedgo"
+)
+
+// loadFixtures reads the gateway messages in testdata/events, one per event type
+func loadFixtures(t *testing.T) map[string]guildedgo.RawEvent {
+	files, err := filepath.Glob("testdata/events/*.json")
+	if err != nil || len(files) == 0 {
+		t.Error("no fixtures found", err)
+		t.FailNow()
+	}
+
+	fixtures := make(map[string]guildedgo.RawEvent)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		var re guildedgo.RawEvent
+		err = json.Unmarshal(data, &re)
+		if err != nil {
+			t.Errorf("%s: %s", file, err)
+			t.FailNow()
+		}
+
+		fixtures[re.T] = re
+	}
+
+	return fixtures
+}
+
+// diff lists the values of want that are missing from got, or differ
+func diff(path string, want, got any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %v", path, got)}
+		}
+
+		var diffs []string
+		for k, v := range w {
+			diffs = append(diffs, diff(path+"."+k, v, g[k])...)
+		}
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return []string{fmt.Sprintf("%s: expected %v, got %v", path, want, got)}
+		}
+
+		var diffs []string
+		for i := range w {
+			diffs = append(diffs, diff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return diffs
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return []string{fmt.Sprintf("%s: expected %v, got %v", path, want, got)}
+		}
+		return nil
+	}
+}
+
+func TestEventFixtures(t *testing.T) {
+	fixtures := loadFixtures(t)
+
+	var messages []string
+	for _, re := range fixtures {
+		msg, _ := json.Marshal(re)
+		messages = append(messages, string(msg))
+	}
+
+	srv := fakeGateway(t, messages...)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	var mu sync.Mutex
+	received := make(map[string]any)
+	done := make(chan struct{})
+
+	c.OnAny(func(client *guildedgo.Client, event string, v any) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if _, ok := fixtures[event]; !ok {
+			return
+		}
+
+		received[event] = v
+		if len(received) == len(fixtures) {
+			close(done)
+		}
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer c.Close()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for events")
+		t.FailNow()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for name, re := range fixtures {
+		v := received[name]
+		if _, ok := v.(*guildedgo.RawEvent); ok {
+			t.Errorf("%s: no type is registered for the event", name)
+			continue
+		}
+
+		if reflect.TypeOf(v).Elem().Name() != name {
+			t.Errorf("%s: decoded into %T", name, v)
+		}
+
+		// Every field of the fixture has to survive decoding and encoding the event again
+		var want, got any
+		json.Unmarshal(re.Data, &want)
+		data, _ := json.Marshal(v)
+		json.Unmarshal(data, &got)
+
+		for _, d := range diff(name, want, got) {
+			t.Error(d)
+		}
+	}
+}
+
+func TestRunStopsWhenContextIsDone(t *testing.T) {
+	srv := fakeGateway(t, chatMessageCreated("1", ""))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled atomic.Bool
+	c.On("ChatMessageCreated", func(client *guildedgo.Client, v any) {
+		cancel()
+
+		// Run waits for handlers that are still busy
+		time.Sleep(100 * time.Millisecond)
+		handled.Store(true)
+	})
+
+	err := c.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+
+	if !handled.Load() {
+		t.Error("expected Run to wait for the handler")
+	}
+}
+
+func TestRunReportsHandlersThatDoNotFinish(t *testing.T) {
+	srv := fakeGateway(t, chatMessageCreated("1", ""))
+	defer srv.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:           "token",
+		WebsocketURL:    "ws" + strings.TrimPrefix(srv.URL, "http"),
+		ShutdownTimeout: 100 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	c.On("ChatMessageCreated", func(client *guildedgo.Client, v any) {
+		cancel()
+		<-release
+	})
+
+	err := c.Run(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error and the shutdown deadline, got %v", err)
+	}
+}
+
+func TestCloseDoesNotWaitForFullQueues(t *testing.T) {
+	srv := fakeGateway(t,
+		chatMessageCreated("1", ""),
+		chatMessageCreated("2", ""),
+		chatMessageCreated("3", ""),
+		chatMessageCreated("4", ""),
+	)
+	defer srv.Close()
+
+	c := guildedgo.NewClient(&guildedgo.Config{
+		Token:           "token",
+		WebsocketURL:    "ws" + strings.TrimPrefix(srv.URL, "http"),
+		Workers:         1,
+		QueueSize:       1,
+		ShutdownTimeout: 200 * time.Millisecond,
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{}, 4)
+	c.On("ChatMessageCreated", func(client *guildedgo.Client, v any) {
+		started <- struct{}{}
+		<-release
+	})
+
+	err := c.Open()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	// The first event blocks the worker, the second fills the queue and reading waits on the third
+	<-started
+	for c.DispatchStats().Queued < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		c.Close()
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(3 * time.Second):
+		t.Error("expected Close to give up after the shutdown timeout")
+		t.FailNow()
+	}
+
+	// Stats isn't blocked by a stuck shutdown either
+	c.Stats()
+}
+
+func TestRunStopsWhenClosed(t *testing.T) {
+	srv := fakeGateway(t)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+	c.On("Connected", func(client *guildedgo.Client, v any) {
+		go client.Close()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, guildedgo.ErrClosed) {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for Run to return")
+	}
+}
+
+func TestRunStopsOnSignal(t *testing.T) {
+	srv := fakeGateway(t)
+	defer srv.Close()
+
+	// Keep the signal from terminating the test if Run hasn't subscribed yet
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	c := newTestClient(srv)
+	c.On("Connected", func(client *guildedgo.Client, v any) {
+		// Keep signalling until Run has received one too
+		go func() {
+			for {
+				syscall.Kill(os.Getpid(), syscall.SIGTERM)
+
+				select {
+				case <-stop:
+					return
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		}()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, guildedgo.ErrInterrupted) {
+			t.Errorf("expected an interrupted error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for Run to return")
+	}
+}
+
+func TestStats(t *testing.T) {
+	srv := fakeGateway(t, chatMessageCreated("1", ""), chatMessageCreated("2", ""))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+	if stats := c.Stats(); len(stats.Events) != 0 {
+		t.Errorf("expected no stats before Open, got %+v", stats)
+	}
+
+	collect(t, c, "ChatMessageCreated", 2)
+
+	stats := c.Stats()
+	if stats.Events["ChatMessageCreated"] != 2 || stats.LastEvent.IsZero() || stats.Reconnects != 0 {
+		t.Errorf("expected 2 ChatMessageCreated events, got %+v", stats)
+	}
+}